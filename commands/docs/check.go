@@ -0,0 +1,330 @@
+package docs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Problem is a broken reference found by Check.
+type Problem struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	htmlLinkPattern     = regexp.MustCompile(`\b(?:href|src)=["']([^"']+)["']`)
+	codeSpanPattern     = regexp.MustCompile("`[^`]*`")
+)
+
+// checker resolves references against the docs filesystem.
+type checker struct {
+	m        *Module
+	anchors  map[string]map[string]bool
+	problems []Problem
+}
+
+// Check parses every markdown page of contentFS and reports links, anchors,
+// directive file paths, menu URLs and redirect targets that do not resolve,
+// and frontmatter not matching the frontmatter schema.
+func Check(contentFS fs.FS, opts ...ModuleOption) ([]Problem, error) {
+	c := &checker{
+		m:       NewModule(contentFS, opts...),
+		anchors: make(map[string]map[string]bool),
+	}
+
+	err := walkMarkdown(contentFS, func(filePath string) error {
+		content, err := fs.ReadFile(contentFS, filePath)
+		if err != nil {
+			return err
+		}
+		c.checkPage(filePath, string(content))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.checkMenu()
+	c.checkRedirects()
+
+	sort.SliceStable(c.problems, func(i, j int) bool {
+		if c.problems[i].File != c.problems[j].File {
+			return c.problems[i].File < c.problems[j].File
+		}
+		return c.problems[i].Line < c.problems[j].Line
+	})
+	return c.problems, nil
+}
+
+// WriteCheck runs Check and writes the problems to w, one per line.
+// It returns an error if any problem was found.
+func WriteCheck(w io.Writer, contentFS fs.FS, opts ...ModuleOption) error {
+	problems, err := Check(contentFS, opts...)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d broken references", len(problems))
+	}
+	return nil
+}
+
+func (c *checker) report(file string, line int, format string, args ...any) {
+	c.problems = append(c.problems, Problem{
+		File:    file,
+		Line:    line,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkPage checks the directives and links of a markdown page.
+func (c *checker) checkPage(filePath, content string) {
+	docDir := path.Dir(filePath)
+	lines := strings.Split(content, "\n")
+
+	if meta, _, err := parseFrontmatter(content); err != nil {
+		c.report(filePath, 1, "invalid frontmatter: %v", err)
+	} else {
+		for _, warning := range meta.Warnings {
+			c.report(filePath, 1, "frontmatter: %s", warning)
+		}
+		if err := c.m.validateFrontmatter(meta.Page); err != nil {
+			c.report(filePath, 1, "%v", err)
+		}
+	}
+
+	start := frontmatterLines(lines)
+	inFence := false
+	for i := start; i < len(lines); i++ {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(lines[i])
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "@") {
+			c.checkDirective(filePath, lineNo, docDir, trimmed)
+			continue
+		}
+
+		text := codeSpanPattern.ReplaceAllString(lines[i], "")
+		for _, pattern := range []*regexp.Regexp{markdownLinkPattern, htmlLinkPattern} {
+			for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+				c.checkLink(filePath, lineNo, docDir, match[1])
+			}
+		}
+	}
+}
+
+// frontmatterLines returns the number of lines taken by frontmatter.
+func frontmatterLines(lines []string) int {
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "---") {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "---") {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// checkDirective checks that the files referenced by a registered
+// directive exist.
+func (c *checker) checkDirective(filePath string, lineNo int, docDir, line string) {
+	name, args, _ := strings.Cut(line, " ")
+	d, ok := c.m.directives[strings.TrimPrefix(name, "@")]
+	if !ok || d.Files == nil {
+		return
+	}
+
+	parts, err := d.ParseArgs(args)
+	if err != nil {
+		c.report(filePath, lineNo, "%s: %v", name, err)
+		return
+	}
+	files, err := d.Files(parts)
+	if err != nil {
+		c.report(filePath, lineNo, "%s: %v", name, err)
+		return
+	}
+
+	for _, file := range files {
+		if _, err := fs.Stat(c.m.FS, path.Join(docDir, file)); err != nil {
+			c.report(filePath, lineNo, "%s: file not found: %s", name, file)
+		}
+	}
+}
+
+// checkLink checks a link target and its anchor.
+func (c *checker) checkLink(filePath string, lineNo int, docDir, link string) {
+	u, err := url.Parse(link)
+	if err != nil {
+		c.report(filePath, lineNo, "invalid link %q: %v", link, err)
+		return
+	}
+	if u.Scheme != "" || u.Host != "" {
+		return
+	}
+
+	target := filePath
+	if u.Path != "" {
+		var ok bool
+		target, ok = c.resolve(docDir, u.Path)
+		if !ok {
+			c.report(filePath, lineNo, "broken link: %s", link)
+			return
+		}
+	}
+
+	if u.Fragment != "" && path.Ext(target) == ".md" && !c.pageAnchors(target)[u.Fragment] {
+		c.report(filePath, lineNo, "broken anchor: %s", link)
+	}
+}
+
+// resolve maps a link path to the file serving it, following the same
+// lookup order as the docs server. Absolute paths are relative to the
+// content root.
+func (c *checker) resolve(docDir, linkPath string) (string, bool) {
+	target := path.Join(docDir, linkPath)
+	if strings.HasPrefix(linkPath, "/") {
+		target = path.Clean(strings.TrimPrefix(linkPath, "/"))
+		if target == "" {
+			target = "."
+		}
+	}
+
+	candidates := []string{target, target + ".md", path.Join(target, "README.md")}
+	for _, candidate := range candidates {
+		info, err := fs.Stat(c.m.FS, candidate)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			if _, err := fs.Stat(c.m.FS, path.Join(candidate, "README.md")); err == nil {
+				return path.Join(candidate, "README.md"), true
+			}
+			if entries, err := fs.ReadDir(c.m.FS, candidate); err == nil && len(entries) > 0 {
+				return candidate, true
+			}
+			continue
+		}
+		return candidate, true
+	}
+	return "", false
+}
+
+// pageAnchors returns the heading IDs of a markdown page.
+func (c *checker) pageAnchors(filePath string) map[string]bool {
+	if anchors, ok := c.anchors[filePath]; ok {
+		return anchors
+	}
+
+	headings := newHeadingIDs()
+	if content, err := fs.ReadFile(c.m.FS, filePath); err == nil {
+		_, body := splitFrontmatter(string(content))
+
+		var lines []string
+		for _, line := range strings.Split(body, "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "@") {
+				lines = append(lines, line)
+			}
+		}
+		renderMarkdownHeadings(strings.Join(lines, "\n"), headings)
+	}
+
+	c.anchors[filePath] = headings.seen
+	return headings.seen
+}
+
+// checkMenu checks the internal URLs of the sidebar menu.
+func (c *checker) checkMenu() {
+	var data map[string]any
+	c.m.fill(&data)
+
+	menuFile := "data/menu.yml"
+	source, _ := fs.ReadFile(c.m.FS, menuFile)
+	lines := strings.Split(string(source), "\n")
+
+	groups, _ := data["menu"].([]any)
+	for _, g := range groups {
+		group, _ := g.(map[string]any)
+		items, _ := group["items"].([]any)
+		for _, item := range items {
+			entry, _ := item.(map[string]any)
+			link, _ := entry["url"].(string)
+			if link == "" {
+				continue
+			}
+
+			u, err := url.Parse(link)
+			if err != nil || u.Scheme != "" || u.Host != "" {
+				continue
+			}
+			if _, ok := c.resolve(".", "/"+strings.TrimPrefix(u.Path, "/")); !ok {
+				c.report(menuFile, lineOf(lines, link), "broken menu link: %s", link)
+			}
+		}
+	}
+}
+
+// checkRedirects checks the _redirects file and its internal targets.
+func (c *checker) checkRedirects() {
+	content, err := fs.ReadFile(c.m.contentFS, redirectsFile)
+	if err != nil {
+		return
+	}
+	redirects, err := parseRedirects(string(content))
+	if err != nil {
+		c.report(redirectsFile, 0, "%v", err)
+		return
+	}
+
+	for _, rd := range redirects {
+		u, err := url.Parse(rd.To)
+		if err != nil {
+			c.report(redirectsFile, rd.Line, "invalid redirect target %q: %v", rd.To, err)
+			continue
+		}
+		if u.Scheme != "" || u.Host != "" {
+			continue
+		}
+		if _, ok := c.resolve(".", "/"+strings.TrimPrefix(u.Path, "/")); !ok {
+			c.report(redirectsFile, rd.Line, "broken redirect: %s", rd.To)
+		}
+	}
+}
+
+// lineOf returns the 1-based line number of the first line containing s,
+// or 0 if no line does.
+func lineOf(lines []string, s string) int {
+	for i, line := range lines {
+		if strings.Contains(line, s) {
+			return i + 1
+		}
+	}
+	return 0
+}
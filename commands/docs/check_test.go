@@ -0,0 +1,133 @@
+package docs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	content := fstest.MapFS{
+		"README.md": &fstest.MapFile{Data: []byte(`---
+title: Home
+---
+# Home
+
+See the [guide](guide/install.md), [usage](/guide/usage#run-it) and [GitHub](https://github.com).
+Jump to [setup](#setup) or [nowhere](#nowhere).
+
+## Setup
+
+` + "`[not a link](missing.md)`" + `
+
+` + "```" + `
+[also not a link](missing.md)
+` + "```" + `
+`)},
+		"guide/install.md": &fstest.MapFile{Data: []byte(`# Install
+
+@file "Code" example.vuego
+@render "Preview" missing.vuego
+@example example.vuego missing.yml
+
+Back [home](../) or to a [missing page](../missing).
+<a href="usage#missing-anchor">usage</a>
+`)},
+		"guide/usage.md":      &fstest.MapFile{Data: []byte("# Usage\n\n## Run it\n")},
+		"guide/example.vuego": &fstest.MapFile{Data: []byte("<div></div>")},
+		"data/menu.yml": &fstest.MapFile{Data: []byte(`menu:
+  - label: Links
+    items:
+      - label: Usage
+        url: /guide/usage
+      - label: Gone
+        url: /gone
+      - label: External
+        url: https://example.com
+`)},
+	}
+
+	problems, err := Check(content, WithMenuMode(MenuManual))
+	require.NoError(t, err)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	require.Equal(t, []string{
+		"README.md:7: broken anchor: #nowhere",
+		"data/menu.yml:7: broken menu link: /gone",
+		"guide/install.md:4: @render: file not found: missing.vuego",
+		"guide/install.md:5: @example: file not found: missing.yml",
+		"guide/install.md:7: broken link: ../missing",
+		"guide/install.md:8: broken anchor: usage#missing-anchor",
+	}, got)
+}
+
+func TestCheck_CustomDirective(t *testing.T) {
+	chart := Directive{
+		Name: "chart",
+		Render: func(context.Context, *DirectiveContext) (DirectiveOutput, error) {
+			return DirectiveOutput{}, nil
+		},
+		Files: func(args []string) ([]string, error) {
+			if len(args) != 1 {
+				return nil, errors.New("expected a data file")
+			}
+			return args, nil
+		},
+	}
+
+	problems, err := Check(fstest.MapFS{
+		"README.md":   &fstest.MapFile{Data: []byte("# Home\n\n@chart sales.csv\n@chart missing.csv\n@chart\n@example editable\n")},
+		"sales.csv":   &fstest.MapFile{Data: []byte("month,total\n")},
+		"check.vuego": &fstest.MapFile{Data: []byte("<div></div>")},
+	}, WithDirective(chart))
+	require.NoError(t, err)
+	require.Equal(t, []Problem{
+		{File: "README.md", Line: 4, Message: "@chart: file not found: missing.csv"},
+		{File: "README.md", Line: 5, Message: "@chart: expected a data file"},
+		{File: "README.md", Line: 6, Message: "@example: missing arguments"},
+	}, problems)
+}
+
+func TestWriteCheck(t *testing.T) {
+	content := fstest.MapFS{
+		"README.md": &fstest.MapFile{Data: []byte("[ok](README.md)\n")},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCheck(&buf, content))
+	require.Empty(t, buf.String())
+
+	content["README.md"] = &fstest.MapFile{Data: []byte("[broken](missing.md)\n")}
+	require.Error(t, WriteCheck(&buf, content))
+	require.Equal(t, "README.md:1: broken link: missing.md\n", buf.String())
+}
+
+func TestNew_Tasks(t *testing.T) {
+	content := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(content, "README.md"), []byte("# Home\n"), 0o644))
+
+	run := func(args ...string) error {
+		cmd := New()
+		cmd.Bind(flag.NewFlagSet("docs", flag.ContinueOnError))
+		return cmd.Run(context.Background(), args)
+	}
+
+	out := filepath.Join(t.TempDir(), "search.json")
+	require.NoError(t, run("search-index", out, content))
+	require.FileExists(t, out)
+
+	require.NoError(t, run("redirect-stubs", t.TempDir(), content))
+	require.NoError(t, run("check", content))
+
+	require.EqualError(t, run("search-index"), "usage: docs search-index <file> [dir]")
+}
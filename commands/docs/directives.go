@@ -0,0 +1,351 @@
+package docs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Directive is an @name line in markdown that renders custom content.
+//
+//	@name arg "quoted arg"
+//
+// Block directives take the lines up to a matching @end line as their body:
+//
+//	@name arg
+//	body
+//	@end
+type Directive struct {
+	// Name is the directive name without the @ prefix.
+	Name string
+	// Block marks directives that take a body terminated by @end.
+	Block bool
+	// ParseArgs splits the argument string. It defaults to splitting on
+	// whitespace with support for double-quoted arguments.
+	ParseArgs func(args string) ([]string, error)
+	// Render renders the directive.
+	Render func(ctx context.Context, dc *DirectiveContext) (DirectiveOutput, error)
+	// Files returns the files the parsed arguments reference, relative to
+	// the page. Check reports the ones that don't exist, and the error
+	// for invalid arguments. Directives without Files are not checked.
+	Files func(args []string) ([]string, error)
+}
+
+// DirectiveContext describes a directive occurrence and gives access to
+// the docs filesystem relative to the page.
+type DirectiveContext struct {
+	// File is the page path within the docs filesystem.
+	File string
+	// Line is the 1-based line of the directive in File.
+	Line int
+	// Source is the directive line.
+	Source string
+	// Args are the parsed directive arguments.
+	Args []string
+	// Body is the body of a block directive.
+	Body string
+
+	module *Module
+	page   *pageParser
+}
+
+// Dir returns the directory of the page, which relative paths resolve from.
+func (dc *DirectiveContext) Dir() string {
+	return path.Dir(dc.File)
+}
+
+// ReadFile reads a file relative to the page.
+func (dc *DirectiveContext) ReadFile(name string) (string, error) {
+	return dc.module.readFile(dc.Dir(), name)
+}
+
+// RenderFile renders a vuego template relative to the page, with its
+// sidecar data and assets.
+func (dc *DirectiveContext) RenderFile(ctx context.Context, name string) (string, error) {
+	return dc.module.renderVuegoFile(ctx, dc.Dir(), name)
+}
+
+// Require includes a partial template on the page once, after the content.
+// Directives use it to load the scripts and styles they depend on.
+func (dc *DirectiveContext) Require(partial string) {
+	dc.page.require(partial)
+}
+
+// RenderTabGroup renders a tab group with the next tab group ID of the page.
+func (dc *DirectiveContext) RenderTabGroup(tg *TabGroup) string {
+	return dc.page.renderTabGroup(tg)
+}
+
+// Arg returns the i-th argument, or an error if it is missing.
+func (dc *DirectiveContext) Arg(i int) (string, error) {
+	if i >= len(dc.Args) {
+		return "", errMissingArgs
+	}
+	return dc.Args[i], nil
+}
+
+// DirectiveOutput is the content a directive renders. Set one field.
+type DirectiveOutput struct {
+	// Tab joins an enclosing @tabs group, or is rendered on its own.
+	Tab *Tab
+	// Tabs are rendered as a tab group of their own.
+	Tabs []Tab
+	// HTML is inserted into the page as is.
+	HTML string
+	// Markdown is rendered as part of the page, including its directives.
+	Markdown string
+	// File is the markdown file Markdown was read from, if any. Its
+	// frontmatter is skipped, its directives resolve paths relative to it
+	// and a file including itself is reported as an include cycle.
+	File string
+}
+
+// errMissingArgs is returned for directives without their required arguments.
+var errMissingArgs = errors.New("missing arguments")
+
+// RegisterDirective adds a directive to the module, replacing a directive
+// with the same name.
+func (m *Module) RegisterDirective(d Directive) error {
+	if d.Name == "" || strings.ContainsAny(d.Name, " \t@") {
+		return fmt.Errorf("invalid directive name %q", d.Name)
+	}
+	if d.Render == nil {
+		return fmt.Errorf("directive @%s: missing Render", d.Name)
+	}
+	if d.Name == "tabs" || d.Name == "end" {
+		return fmt.Errorf("directive @%s is reserved", d.Name)
+	}
+	if d.ParseArgs == nil {
+		d.ParseArgs = func(args string) ([]string, error) {
+			return parseDirectiveParts(args), nil
+		}
+	}
+	m.directives[d.Name] = d
+	return nil
+}
+
+// WithDirective registers a directive on the module. It panics if the
+// directive is invalid.
+func WithDirective(d Directive) ModuleOption {
+	return func(m *Module) {
+		if err := m.RegisterDirective(d); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// builtinDirectives returns the directives every module supports.
+func builtinDirectives() []Directive {
+	return []Directive{
+		{Name: "render", Render: renderDirective, Files: labeledFile},
+		{Name: "file", Render: fileDirective, Files: labeledFile},
+		{Name: "example", Render: exampleDirective, Files: exampleFiles},
+		{Name: "include-md", Render: includeMarkdownDirective, Files: firstFile},
+	}
+}
+
+// labeledFile returns the file of directives taking a label and a file.
+func labeledFile(args []string) ([]string, error) {
+	if len(args) < 2 {
+		return nil, errMissingArgs
+	}
+	return args[1:2], nil
+}
+
+// firstFile returns the file of directives taking a file first.
+func firstFile(args []string) ([]string, error) {
+	if len(args) < 1 {
+		return nil, errMissingArgs
+	}
+	return args[:1], nil
+}
+
+// renderDirective renders a vuego template as a preview tab.
+//
+//	@render "Label" file.vuego
+func renderDirective(ctx context.Context, dc *DirectiveContext) (DirectiveOutput, error) {
+	if len(dc.Args) < 2 {
+		return DirectiveOutput{}, errMissingArgs
+	}
+	label, filePath := dc.Args[0], dc.Args[1]
+
+	rendered, err := dc.RenderFile(ctx, filePath)
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+	return DirectiveOutput{
+		Tab: &Tab{Label: label, Content: rendered, IsCode: false},
+	}, nil
+}
+
+// fileDirective shows the source of a file as a code tab. Options select
+// a named region or a line range and highlight lines of the result.
+//
+//	@file "Label" file.vuego
+//	@file "Label" main.go region=setup highlight=2,4-5
+//	@file "Label" main.go lines=10-20
+func fileDirective(_ context.Context, dc *DirectiveContext) (DirectiveOutput, error) {
+	if len(dc.Args) < 2 {
+		return DirectiveOutput{}, errMissingArgs
+	}
+	label, filePath := dc.Args[0], dc.Args[1]
+
+	opts, err := parseSnippetOptions(dc.Args[2:])
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+
+	content, err := dc.ReadFile(filePath)
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+	content, err = opts.apply(content)
+	if err != nil {
+		return DirectiveOutput{}, fmt.Errorf("%s: %w", filePath, err)
+	}
+
+	return DirectiveOutput{
+		Tab: &Tab{
+			Label:     label,
+			Content:   content,
+			IsCode:    true,
+			Mode:      editorMode(filePath),
+			Highlight: opts.Highlight,
+		},
+	}, nil
+}
+
+// includeMarkdownDirective renders another markdown file in place,
+// including its directives.
+//
+//	@include-md shared/intro.md
+func includeMarkdownDirective(_ context.Context, dc *DirectiveContext) (DirectiveOutput, error) {
+	filePath, err := dc.Arg(0)
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+
+	content, err := dc.ReadFile(filePath)
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+	return DirectiveOutput{
+		Markdown: content,
+		File:     path.Join(dc.Dir(), filePath),
+	}, nil
+}
+
+// exampleRenderURL is the docs endpoint editable examples render through.
+const exampleRenderURL = "/render"
+
+// exampleDirective renders a template with preview and code tabs. With the
+// editable flag the code and data are shown in editors and the preview is
+// re-rendered through the docs render endpoint as they change. The data
+// file defaults to the data sidecar of the template.
+//
+//	@example file.vuego
+//	@example file.vuego file.yaml editable
+func exampleDirective(ctx context.Context, dc *DirectiveContext) (DirectiveOutput, error) {
+	args, editable := exampleArgs(dc.Args)
+	if len(args) < 1 {
+		return DirectiveOutput{}, errMissingArgs
+	}
+	vuegoPart := args[0]
+
+	rendered, err := dc.RenderFile(ctx, vuegoPart)
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+	code, err := dc.ReadFile(vuegoPart)
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+
+	if !editable {
+		return DirectiveOutput{
+			Tabs: []Tab{
+				{Label: "Preview", Content: rendered, IsCode: false},
+				{Label: "Code", Content: code, IsCode: true, Mode: "html"},
+			},
+		}, nil
+	}
+
+	tabs := []Tab{
+		{Label: "Preview", Content: `<div class="w-full" data-example-preview>` + rendered + `</div>`},
+		{Label: "Code", Content: code, IsCode: true, Mode: "html", Editor: "template"},
+	}
+
+	dataPath, err := exampleDataFile(dc, vuegoPart, args[1:])
+	if err != nil {
+		return DirectiveOutput{}, err
+	}
+	if dataPath != "" {
+		data, err := dc.ReadFile(dataPath)
+		if err != nil {
+			return DirectiveOutput{}, err
+		}
+		tabs = append(tabs, Tab{Label: "Data", Content: data, IsCode: true, Mode: editorMode(dataPath), Editor: "data"})
+	}
+
+	dc.Require("partials/ace.vuego")
+	dc.Require("partials/example.vuego")
+
+	return DirectiveOutput{
+		HTML: fmt.Sprintf(
+			`<div data-example data-name="%s" data-render-url="%s">%s</div>`,
+			html.EscapeString(path.Join(dc.Dir(), vuegoPart)),
+			dc.module.url(exampleRenderURL),
+			dc.RenderTabGroup(&TabGroup{Tabs: tabs}),
+		),
+	}, nil
+}
+
+// exampleArgs strips the trailing editable flag from @example arguments.
+func exampleArgs(args []string) ([]string, bool) {
+	if len(args) > 0 && args[len(args)-1] == "editable" {
+		return args[:len(args)-1], true
+	}
+	return args, false
+}
+
+// exampleFiles returns the files of an @example directive.
+func exampleFiles(args []string) ([]string, error) {
+	files, _ := exampleArgs(args)
+	if len(files) < 1 {
+		return nil, errMissingArgs
+	}
+	return files, nil
+}
+
+// exampleDataFile returns the data file of an example: the explicit
+// argument, or the first existing .yaml, .yml or .json sidecar.
+func exampleDataFile(dc *DirectiveContext, vuegoPart string, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	baseName := strings.TrimSuffix(vuegoPart, filepath.Ext(vuegoPart))
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		if _, err := fs.Stat(dc.module.FS, path.Join(dc.Dir(), baseName+ext)); err == nil {
+			return baseName + ext, nil
+		}
+	}
+	return "", nil
+}
+
+// editorMode returns the Ace editor mode for a file name.
+func editorMode(filePath string) string {
+	mode := strings.TrimPrefix(filepath.Ext(filePath), ".")
+	switch mode {
+	case "vuego":
+		return "html"
+	case "yml":
+		return "yaml"
+	}
+	return mode
+}
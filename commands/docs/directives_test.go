@@ -0,0 +1,160 @@
+package docs
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModule_RegisterDirective(t *testing.T) {
+	m := NewModule(fstest.MapFS{})
+
+	render := func(context.Context, *DirectiveContext) (DirectiveOutput, error) {
+		return DirectiveOutput{}, nil
+	}
+
+	require.NoError(t, m.RegisterDirective(Directive{Name: "api", Render: render}))
+	require.Error(t, m.RegisterDirective(Directive{Name: "", Render: render}))
+	require.Error(t, m.RegisterDirective(Directive{Name: "two words", Render: render}))
+	require.Error(t, m.RegisterDirective(Directive{Name: "api"}))
+	require.Error(t, m.RegisterDirective(Directive{Name: "tabs", Render: render}))
+}
+
+func TestParseDirectives_Custom(t *testing.T) {
+	upper := Directive{
+		Name: "upper",
+		Render: func(_ context.Context, dc *DirectiveContext) (DirectiveOutput, error) {
+			return DirectiveOutput{HTML: "<p>" + html.EscapeString(strings.ToUpper(strings.Join(dc.Args, " "))) + "</p>"}, nil
+		},
+	}
+	note := Directive{
+		Name:  "note",
+		Block: true,
+		Render: func(_ context.Context, dc *DirectiveContext) (DirectiveOutput, error) {
+			return DirectiveOutput{Markdown: "## " + dc.Args[0] + "\n\n" + dc.Body}, nil
+		},
+	}
+
+	m := NewModule(fstest.MapFS{}, WithDirective(upper), WithDirective(note), WithStrict(true))
+
+	body := strings.Join([]string{
+		"# Page",
+		`@upper hello "big world"`,
+		"@note Heads",
+		"Outer *body*.",
+		"@note Inner",
+		"Inner body.",
+		"@end",
+		"@end",
+		"@someone mentioned",
+	}, "\n")
+
+	doc, err := m.parseDirectives(context.Background(), "page.md", body, 0)
+	require.NoError(t, err)
+	require.Contains(t, doc.Content, "<p>HELLO BIG WORLD</p>")
+	require.Contains(t, doc.Content, `<h2 id="heads">`)
+	require.Contains(t, doc.Content, "<em>body</em>")
+	require.Contains(t, doc.Content, `<h2 id="inner">`)
+	require.Contains(t, doc.Content, "Inner body.")
+	require.Contains(t, doc.Content, "@someone mentioned")
+	require.NotContains(t, doc.Content, "@end")
+	require.Len(t, doc.TOC, 2)
+}
+
+func TestParseDirectives_BlockWithoutEnd(t *testing.T) {
+	block := Directive{
+		Name:  "box",
+		Block: true,
+		Render: func(context.Context, *DirectiveContext) (DirectiveOutput, error) {
+			return DirectiveOutput{HTML: "<div></div>"}, nil
+		},
+	}
+
+	m := NewModule(fstest.MapFS{}, WithDirective(block), WithStrict(true))
+
+	_, err := m.parseDirectives(context.Background(), "page.md", "text\n\n@box\nbody", 2)
+	require.Error(t, err)
+
+	var directiveErr *DirectiveError
+	require.True(t, errors.As(err, &directiveErr))
+	require.Equal(t, 5, directiveErr.Line)
+	require.Contains(t, err.Error(), "missing @end")
+}
+
+func TestParseDirectives_FileTab(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"docs/data.yml": &fstest.MapFile{Data: []byte("a: <b>\n")},
+	})
+
+	doc, err := m.parseDirectives(context.Background(), "docs/page.md", `@file "Data" data.yml`, 0)
+	require.NoError(t, err)
+	require.Contains(t, doc.Content, `class="language-yaml`)
+	require.Contains(t, doc.Content, `<span class="l">&lt;b&gt;</span>`)
+}
+
+func TestParseDirectives_FileRegion(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"main.go": &fstest.MapFile{Data: []byte(snippetSource)},
+	}, WithStrict(true))
+
+	doc, err := m.parseDirectives(context.Background(), "page.md", `@file "Setup" main.go region=setup highlight=2`, 0)
+	require.NoError(t, err)
+	require.Contains(t, doc.Content, `class="language-go`)
+	require.Contains(t, doc.Content, `<span class="code-line highlighted"><span class="w">	</span><span class="nx">x</span>`)
+	require.NotContains(t, doc.Content, "func main")
+
+	_, err = m.parseDirectives(context.Background(), "page.md", `@file "Setup" main.go region=nope`, 0)
+	require.ErrorContains(t, err, `main.go: region "nope" not found`)
+}
+
+func TestParseDirectives_IncludeMarkdown(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"shared/intro.md": &fstest.MapFile{Data: []byte("---\ntitle: Intro\n---\n## Intro\n\nShared text.\n\n@include-md note.md\n")},
+		"shared/note.md":  &fstest.MapFile{Data: []byte("## Note\n\n@file \"Data\" data.yml\n")},
+		"shared/data.yml": &fstest.MapFile{Data: []byte("a: 1\n")},
+	}, WithStrict(true))
+
+	doc, err := m.parseDirectives(context.Background(), "guide/page.md", "# Page\n\n@include-md ../shared/intro.md\n\n## Intro\n", 0)
+	require.NoError(t, err)
+	require.Contains(t, doc.Content, "Shared text.")
+	require.NotContains(t, doc.Content, "title: Intro")
+	require.Contains(t, doc.Content, `<span class="nt">a</span>`)
+	require.Contains(t, doc.Content, `<h2 id="intro-1">`)
+	require.Len(t, doc.TOC, 3)
+}
+
+func TestParseDirectives_IncludeCycle(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"a.md": &fstest.MapFile{Data: []byte("A\n\n@include-md b.md\n")},
+		"b.md": &fstest.MapFile{Data: []byte("B\n\n@include-md a.md\n")},
+	}, WithStrict(true))
+
+	_, err := m.parseDirectives(context.Background(), "a.md", "A\n\n@include-md b.md\n", 0)
+	require.Error(t, err)
+
+	var directiveErr *DirectiveError
+	require.True(t, errors.As(err, &directiveErr))
+	require.Equal(t, "b.md", directiveErr.File)
+	require.Equal(t, 3, directiveErr.Line)
+	require.Contains(t, err.Error(), "include cycle: a.md -> b.md -> a.md")
+}
+
+func TestParseDirectives_EditableExample(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"components/card.vuego": &fstest.MapFile{Data: []byte(`<div class="card">{{ title }}</div>`)},
+		"components/card.yml":   &fstest.MapFile{Data: []byte("title: Hello\n")},
+	}, WithStrict(true))
+
+	doc, err := m.parseDirectives(context.Background(), "page.md", "@example components/card.vuego editable", 0)
+	require.NoError(t, err)
+	require.Contains(t, doc.Content, `data-example data-name="components/card.vuego" data-render-url="/render"`)
+	require.Contains(t, doc.Content, "data-example-preview")
+	require.Contains(t, doc.Content, `data-example-editor="template" data-mode="html">&lt;div class=&#34;card&#34;&gt;`)
+	require.Contains(t, doc.Content, `data-example-editor="data" data-mode="yaml">title: Hello`)
+	require.Equal(t, []string{"partials/ace.vuego", "partials/example.vuego"}, doc.Partials)
+}
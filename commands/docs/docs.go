@@ -2,6 +2,8 @@ package docs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 
@@ -15,32 +17,115 @@ const Name = "Start documentation server for markdown files"
 
 // New creates a new docs command.
 func New() *cli.Command {
-	var addr string
+	var addr, menu, defaultVersion, defaultLocale, feed, baseURL, editURL string
+	var versions, locales []string
+	var strict, drafts bool
 
 	return &cli.Command{
 		Name:  "docs",
 		Title: Name,
 		Bind: func(fs *flag.FlagSet) {
 			fs.StringVar(&addr, "addr", ":8080", "HTTP server address")
+			fs.StringVar(&menu, "menu", string(MenuMerge), "Sidebar menu source: merge (docs tree and data/menu.yml), auto or manual")
+			fs.BoolVar(&strict, "strict", false, "Fail pages with broken @render, @file or @example directives")
+			fs.StringVar(&feed, "feed", "", "Publish the pages below a directory as Atom (/atom.xml) and RSS (/rss.xml) feeds")
+			fs.BoolVar(&drafts, "drafts", false, "Include draft pages in the sitemap and feeds")
+			fs.StringVar(&baseURL, "base-url", "", "Absolute URL of the docs for sitemap and feed links (default: the request URL)")
+			fs.StringVar(&editURL, "edit-url", "", "Edit this page link of pages, with {path} replaced by the page file and {version} by the docs version")
+			fs.StringArrayVar(&versions, "version", nil, "Serve a docs version under /v/{name}/ as name=dir or name:label=dir (repeatable)")
+			fs.StringVar(&defaultVersion, "default-version", "", "Version unversioned URLs redirect to (default: the first version)")
+			fs.StringArrayVar(&locales, "locale", nil, "Serve a docs locale as code or code=label, other than the default under /{code}/ (repeatable)")
+			fs.StringVar(&defaultLocale, "default-locale", "", "Locale served at the docs root (default: the first locale)")
 		},
 		Run: func(ctx context.Context, args []string) error {
-			dir := "."
+			menuMode, err := ParseMenuMode(menu)
+			if err != nil {
+				return err
+			}
+
+			// One-shot tasks run instead of the server. Content in a
+			// directory named like a task is served as ./check.
 			if len(args) > 0 {
-				dir = args[0]
+				switch args[0] {
+				case "check":
+					// docs check [dir]: report broken references and fail for CI.
+					return WriteCheck(os.Stdout, os.DirFS(argOr(args, 1, ".")), WithMenuMode(menuMode))
+				case "search-index":
+					// docs search-index <file> [dir]
+					if len(args) < 2 {
+						return errors.New("usage: docs search-index <file> [dir]")
+					}
+					return WriteSearchIndex(argOr(args, 2, "."), args[1])
+				case "redirect-stubs":
+					// docs redirect-stubs <out-dir> [dir]
+					if len(args) < 2 {
+						return errors.New("usage: docs redirect-stubs <out-dir> [dir]")
+					}
+					return WriteRedirectStubs(argOr(args, 2, "."), args[1])
+				}
+			}
+
+			dir := argOr(args, 0, ".")
+			moduleOpts := []ModuleOption{
+				WithMenuMode(menuMode),
+				WithStrict(strict),
+				WithDrafts(drafts),
+				WithBaseURL(baseURL),
+				WithContentDir(dir),
+				WithEditURL(editURL),
 			}
-			return Serve(ctx, addr, dir)
+			if feed != "" {
+				moduleOpts = append(moduleOpts, WithFeed(feed))
+			}
+			if len(versions) > 0 {
+				var docVersions []Version
+				for _, value := range versions {
+					v, err := ParseVersion(value)
+					if err != nil {
+						return err
+					}
+					docVersions = append(docVersions, v)
+				}
+				if defaultVersion == "" {
+					defaultVersion = docVersions[0].Name
+				}
+				moduleOpts = append(moduleOpts, WithVersions(defaultVersion, docVersions...))
+			}
+			if len(locales) > 0 {
+				var docLocales []Locale
+				for _, value := range locales {
+					l, err := ParseLocale(value)
+					if err != nil {
+						return err
+					}
+					docLocales = append(docLocales, l)
+				}
+				if defaultLocale == "" {
+					defaultLocale = docLocales[0].Code
+				}
+				moduleOpts = append(moduleOpts, WithLocales(defaultLocale, docLocales...))
+			}
+			return Serve(ctx, addr, dir, moduleOpts...)
 		},
 	}
 }
 
+// argOr returns args[i], or fallback if there are fewer arguments.
+func argOr(args []string, i int, fallback string) string {
+	if len(args) > i {
+		return args[i]
+	}
+	return fallback
+}
+
 // Serve starts the docs server using the platform.
-func Serve(ctx context.Context, addr string, contentPath string) error {
+func Serve(ctx context.Context, addr string, contentPath string, moduleOpts ...ModuleOption) error {
 	opts := platform.NewOptions()
 	opts.ServerAddr = addr
 
 	log.Printf("Serving docs from: %s", contentPath)
 	contentFS := os.DirFS(contentPath)
-	docsModule := NewModule(contentFS)
+	docsModule := NewModule(contentFS, moduleOpts...)
 
 	p := platform.New(opts)
 	p.Register(docsModule)
@@ -52,3 +137,17 @@ func Serve(ctx context.Context, addr string, contentPath string) error {
 	p.Wait()
 	return nil
 }
+
+// WriteSearchIndex writes the search index for contentPath to filename.
+func WriteSearchIndex(contentPath, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := ExportSearchIndex(os.DirFS(contentPath), f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing search index: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,52 @@
+package docs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+)
+
+// DirectiveError is a failed @render, @file or @example directive.
+type DirectiveError struct {
+	File      string
+	Line      int
+	Directive string
+	Err       error
+}
+
+func (e *DirectiveError) Error() string {
+	return fmt.Sprintf("%s:%d: %s: %v", e.File, e.Line, e.Directive, e.Err)
+}
+
+func (e *DirectiveError) Unwrap() error { return e.Err }
+
+// renderDirectiveError renders a directive error as a destructive basecoat
+// alert, showing the error and the directive source line.
+func (m *Module) renderDirectiveError(ctx context.Context, err *DirectiveError) string {
+	return m.renderErrorAlert(ctx, err.Err.Error(), fmt.Sprintf("%s:%d: %s", err.File, err.Line, err.Directive))
+}
+
+// renderErrorAlert renders a destructive basecoat alert with an error
+// title and a description locating it.
+func (m *Module) renderErrorAlert(ctx context.Context, title, description string) string {
+	data := map[string]any{
+		"alert": map[string]any{
+			"class":       "alert-destructive my-6",
+			"icon":        "circle-alert",
+			"title":       title,
+			"description": description,
+		},
+	}
+
+	var buf bytes.Buffer
+	if renderErr := m.vuego.Load("components/alert.vuego").Fill(data).Render(ctx, &buf); renderErr == nil {
+		return buf.String()
+	}
+
+	return fmt.Sprintf(
+		`<div role="alert" class="alert-destructive my-6"><h2>%s</h2><section>%s</section></div>`,
+		html.EscapeString(title),
+		html.EscapeString(description),
+	)
+}
@@ -0,0 +1,56 @@
+package docs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const brokenDirectiveDoc = `---
+title: Guide
+---
+# Guide
+
+@file "Code" missing.vuego
+`
+
+func TestParseDirectives_Strict(t *testing.T) {
+	m := NewModule(fstest.MapFS{}, WithStrict(true))
+
+	_, body, err := parseFrontmatter(brokenDirectiveDoc)
+	require.NoError(t, err)
+
+	_, err = m.parseDirectives(context.Background(), "guide.md", body, bodyLineOffset(brokenDirectiveDoc, body))
+	require.Error(t, err)
+
+	var directiveErr *DirectiveError
+	require.True(t, errors.As(err, &directiveErr))
+	require.Equal(t, "guide.md", directiveErr.File)
+	require.Equal(t, 6, directiveErr.Line)
+	require.Equal(t, `@file "Code" missing.vuego`, directiveErr.Directive)
+	require.Contains(t, err.Error(), "guide.md:6:")
+}
+
+func TestParseDirectives_Alert(t *testing.T) {
+	m := NewModule(fstest.MapFS{})
+
+	_, body, err := parseFrontmatter(brokenDirectiveDoc)
+	require.NoError(t, err)
+
+	doc, err := m.parseDirectives(context.Background(), "guide.md", body, bodyLineOffset(brokenDirectiveDoc, body))
+	require.NoError(t, err)
+	require.Contains(t, doc.Content, `role="alert"`)
+	require.Contains(t, doc.Content, "reading missing.vuego")
+	require.Contains(t, doc.Content, "guide.md:6:")
+	require.NotContains(t, doc.Content, "<!--")
+}
+
+func TestBodyLineOffset(t *testing.T) {
+	_, body, err := parseFrontmatter(brokenDirectiveDoc)
+	require.NoError(t, err)
+	require.Equal(t, 3, bodyLineOffset(brokenDirectiveDoc, body))
+	require.Equal(t, 0, bodyLineOffset("text", "text"))
+}
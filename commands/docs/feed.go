@@ -0,0 +1,378 @@
+package docs
+
+import (
+	"encoding/xml"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sitemapURL = "/sitemap.xml"
+	atomURL    = "/atom.xml"
+	rssURL     = "/rss.xml"
+
+	// feedLimit is the number of newest pages in a feed.
+	feedLimit = 20
+	// feedSummaryLength is the length of summaries taken from page text.
+	feedSummaryLength = 280
+)
+
+// WithFeed publishes the pages below dir, such as a changelog or blog,
+// as Atom and RSS feeds, newest first by their date.
+func WithFeed(dir string) ModuleOption {
+	return func(m *Module) {
+		m.feedDir = path.Clean(strings.Trim(dir, "/"))
+	}
+}
+
+// WithDrafts includes pages marked as draft in the sitemap and feeds.
+func WithDrafts(drafts bool) ModuleOption {
+	return func(m *Module) {
+		m.drafts = drafts
+	}
+}
+
+// WithBaseURL sets the absolute URL the docs are published at, used for
+// links in the sitemap and feeds. It defaults to the URL of the request.
+func WithBaseURL(baseURL string) ModuleOption {
+	return func(m *Module) {
+		m.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// feedPage is a markdown page listed in the sitemap, feeds and tags.
+type feedPage struct {
+	Path    string
+	URL     string
+	Title   string
+	Summary string
+	Meta    DocMeta
+	// Updated is the page date, or the file modification time.
+	Updated time.Time
+}
+
+// feedCache holds the feed pages until the markdown files change, checked
+// at most every searchRefreshInterval.
+type feedCache struct {
+	mu        sync.Mutex
+	pages     []feedPage
+	built     bool
+	signature string
+	checked   time.Time
+}
+
+// feedPages lists the markdown pages of the module, without drafts
+// unless they are enabled. Pages with invalid frontmatter are skipped.
+// The list is read again when the markdown files change; callers get a
+// copy they may sort.
+func (m *Module) feedPages() ([]feedPage, error) {
+	c := &m.feedCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.built || time.Since(c.checked) >= searchRefreshInterval {
+		signature, err := markdownSignature(m.contentFS)
+		if err != nil {
+			return nil, err
+		}
+		c.checked = time.Now()
+		if !c.built || signature != c.signature {
+			pages, err := m.readFeedPages()
+			if err != nil {
+				return nil, err
+			}
+			c.pages, c.built, c.signature = pages, true, signature
+		}
+	}
+	return slices.Clone(c.pages), nil
+}
+
+// readFeedPages reads the feed pages from the markdown files.
+func (m *Module) readFeedPages() ([]feedPage, error) {
+	var pages []feedPage
+	err := walkMarkdown(m.contentFS, func(filePath string) error {
+		if path.Base(filePath) == "_index.md" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(m.contentFS, filePath)
+		if err != nil {
+			return err
+		}
+		meta, _, err := parseFrontmatter(string(content))
+		if err != nil {
+			log.Printf("skipping %s: %v", filePath, err)
+			return nil
+		}
+		if meta.Draft && !m.drafts {
+			return nil
+		}
+		doc, err := indexDocument(filePath, string(content))
+		if err != nil {
+			log.Printf("skipping %s: %v", filePath, err)
+			return nil
+		}
+
+		page := feedPage{
+			Path:    filePath,
+			URL:     m.url(doc.URL),
+			Title:   doc.Title,
+			Summary: meta.Description,
+			Meta:    meta,
+			Updated: meta.Date,
+		}
+		if page.Summary == "" {
+			page.Summary = meta.Subtitle
+		}
+		if page.Summary == "" {
+			page.Summary = truncateText(doc.Text, feedSummaryLength)
+		}
+		if page.Updated.IsZero() {
+			if info, err := fs.Stat(m.contentFS, filePath); err == nil {
+				page.Updated = info.ModTime()
+			}
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	return pages, err
+}
+
+// truncateText shortens text to at most n characters at a word boundary.
+func truncateText(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	text = string(runes[:n])
+	if i := strings.LastIndex(text, " "); i > 0 {
+		text = text[:i]
+	}
+	return text + "…"
+}
+
+// absoluteURL returns the absolute URL of a docs path.
+func (m *Module) absoluteURL(r *http.Request, p string) string {
+	if m.baseURL != "" {
+		return m.baseURL + p
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + p
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name       `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func (m *Module) serveSitemap(w http.ResponseWriter, r *http.Request) error {
+	pages, err := m.feedPages()
+	if err != nil {
+		return err
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].URL < pages[j].URL
+	})
+
+	urlset := sitemapURLSet{}
+	for _, page := range pages {
+		entry := sitemapEntry{Loc: m.absoluteURL(r, page.URL)}
+		if !page.Updated.IsZero() {
+			entry.LastMod = page.Updated.UTC().Format("2006-01-02")
+		}
+		urlset.URLs = append(urlset.URLs, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	return writeXML(w, urlset)
+}
+
+// feed returns the title and the newest pages of the feed directory.
+func (m *Module) feed() (string, []feedPage, error) {
+	pages, err := m.feedPages()
+	if err != nil {
+		return "", nil, err
+	}
+
+	title := path.Base(m.feedDir)
+	if meta, err := readIndexMeta(m.contentFS, m.feedDir); err == nil && meta.Label != "" {
+		title = meta.Label
+	}
+
+	var entries []feedPage
+	for _, page := range pages {
+		if page.Path == path.Join(m.feedDir, "README.md") {
+			title = page.Title
+			continue
+		}
+		if m.feedDir == "." || strings.HasPrefix(page.Path, m.feedDir+"/") {
+			entries = append(entries, page)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Updated.After(entries[j].Updated)
+	})
+	if len(entries) > feedLimit {
+		entries = entries[:feedLimit]
+	}
+	return title, entries, nil
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Link       atomLink       `xml:"link"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func (m *Module) serveAtom(w http.ResponseWriter, r *http.Request) error {
+	title, pages, err := m.feed()
+	if err != nil {
+		return err
+	}
+
+	feed := atomFeed{
+		Title: title,
+		ID:    m.absoluteURL(r, m.url(docURL(path.Join(m.feedDir, "README.md")))),
+		Links: []atomLink{
+			{Href: m.absoluteURL(r, m.url(atomURL)), Rel: "self"},
+			{Href: m.absoluteURL(r, m.url(docURL(path.Join(m.feedDir, "README.md"))))},
+		},
+	}
+	var updated time.Time
+	for _, page := range pages {
+		if page.Updated.After(updated) {
+			updated = page.Updated
+		}
+
+		link := m.absoluteURL(r, page.URL)
+		entry := atomEntry{
+			Title:   page.Title,
+			ID:      link,
+			Updated: page.Updated.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: link},
+			Summary: page.Summary,
+		}
+		if page.Meta.Author != "" {
+			entry.Author = &atomAuthor{Name: page.Meta.Author}
+		}
+		for _, tag := range page.Meta.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	return writeXML(w, feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description,omitempty"`
+}
+
+func (m *Module) serveRSS(w http.ResponseWriter, r *http.Request) error {
+	title, pages, err := m.feed()
+	if err != nil {
+		return err
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        m.absoluteURL(r, m.url(docURL(path.Join(m.feedDir, "README.md")))),
+			Description: title,
+		},
+	}
+	for _, page := range pages {
+		link := m.absoluteURL(r, page.URL)
+		item := rssItem{
+			Title:       page.Title,
+			Link:        link,
+			GUID:        link,
+			Categories:  page.Meta.Tags,
+			Description: page.Summary,
+		}
+		if !page.Updated.IsZero() {
+			item.PubDate = page.Updated.UTC().Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
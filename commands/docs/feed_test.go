@@ -0,0 +1,171 @@
+package docs
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func serveFeed(t *testing.T, m *Module, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := chi.NewRouter()
+	require.NoError(t, m.Mount(context.Background(), r))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestSitemap(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	rec := serveFeed(t, NewModule(fstest.MapFS{
+		"README.md":            &fstest.MapFile{Data: []byte("# Home\n"), ModTime: modTime},
+		"guide.md":             &fstest.MapFile{Data: []byte("# Guide\n"), ModTime: modTime},
+		"changelog/README.md":  &fstest.MapFile{Data: []byte("---\ntitle: Changelog\n---\n"), ModTime: modTime},
+		"changelog/v1.0.md":    &fstest.MapFile{Data: []byte("---\ntitle: Version 1.0\ndate: 2024-01-10\nauthor: Jane\ntags: [release]\n---\nFirst stable release.\n")},
+		"changelog/v1.1.md":    &fstest.MapFile{Data: []byte("---\ntitle: Version 1.1\ndate: 2024-03-02\ndescription: Faster rendering.\nsubtitle: Release notes.\n---\nBody.\n")},
+		"changelog/v2.0.md":    &fstest.MapFile{Data: []byte("---\ntitle: Version 2.0\ndate: 2024-06-01\ndraft: true\n---\nUnreleased.\n")},
+		"changelog/_index.yml": &fstest.MapFile{Data: []byte("title: Releases\n")},
+	}), "/sitemap.xml")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/xml; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	var urlset sitemapURLSet
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &urlset))
+
+	var locs []string
+	for _, u := range urlset.URLs {
+		locs = append(locs, u.Loc)
+	}
+	require.Equal(t, []string{
+		"http://example.com/",
+		"http://example.com/changelog",
+		"http://example.com/changelog/v1.0",
+		"http://example.com/changelog/v1.1",
+		"http://example.com/guide",
+	}, locs)
+	require.Equal(t, "2024-01-10", urlset.URLs[2].LastMod)
+	require.Equal(t, "2024-05-01", urlset.URLs[4].LastMod)
+}
+
+func TestSitemap_Drafts(t *testing.T) {
+	rec := serveFeed(t, NewModule(fstest.MapFS{
+		"README.md":         &fstest.MapFile{Data: []byte("# Home\n")},
+		"changelog/v2.0.md": &fstest.MapFile{Data: []byte("---\ntitle: Version 2.0\ndraft: true\n---\nUnreleased.\n")},
+	}, WithDrafts(true), WithBaseURL("https://docs.example.com/")), "/sitemap.xml")
+	require.Contains(t, rec.Body.String(), "<loc>https://docs.example.com/changelog/v2.0</loc>")
+}
+
+func TestFeed_Atom(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"changelog/README.md": &fstest.MapFile{Data: []byte("---\ntitle: Changelog\n---\n")},
+		"changelog/v1.0.md":   &fstest.MapFile{Data: []byte("---\ntitle: Version 1.0\ndate: 2024-01-10\nauthor: Jane\ntags: [release]\n---\nFirst stable release.\n")},
+		"changelog/v1.1.md":   &fstest.MapFile{Data: []byte("---\ntitle: Version 1.1\ndate: 2024-03-02\ndescription: Faster rendering.\nsubtitle: Release notes.\n---\nBody.\n")},
+		"changelog/v2.0.md":   &fstest.MapFile{Data: []byte("---\ntitle: Version 2.0\ndate: 2024-06-01\ndraft: true\n---\nUnreleased.\n")},
+	}, WithFeed("changelog"), WithBaseURL("https://docs.example.com"))
+	rec := serveFeed(t, m, "/atom.xml")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var feed atomFeed
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &feed))
+	require.Equal(t, "Changelog", feed.Title)
+	require.Equal(t, "2024-03-02T00:00:00Z", feed.Updated)
+	require.Len(t, feed.Entries, 2)
+
+	require.Equal(t, "Version 1.1", feed.Entries[0].Title)
+	require.Equal(t, "Faster rendering.", feed.Entries[0].Summary)
+	require.Nil(t, feed.Entries[0].Author)
+
+	require.Equal(t, "https://docs.example.com/changelog/v1.0", feed.Entries[1].ID)
+	require.Equal(t, "Jane", feed.Entries[1].Author.Name)
+	require.Equal(t, []atomCategory{{Term: "release"}}, feed.Entries[1].Categories)
+	require.Contains(t, feed.Entries[1].Summary, "First stable release.")
+}
+
+func TestFeed_RSS(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"changelog/README.md": &fstest.MapFile{Data: []byte("---\ntitle: Changelog\n---\n")},
+		"changelog/v1.0.md":   &fstest.MapFile{Data: []byte("---\ntitle: Version 1.0\ndate: 2024-01-10\nauthor: Jane\ntags: [release]\n---\nFirst stable release.\n")},
+		"changelog/v1.1.md":   &fstest.MapFile{Data: []byte("---\ntitle: Version 1.1\ndate: 2024-03-02\ndescription: Faster rendering.\nsubtitle: Release notes.\n---\nBody.\n")},
+		"changelog/v2.0.md":   &fstest.MapFile{Data: []byte("---\ntitle: Version 2.0\ndate: 2024-06-01\ndraft: true\n---\nUnreleased.\n")},
+	}, WithFeed("/changelog/"), WithDrafts(true))
+	rec := serveFeed(t, m, "/rss.xml")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var feed rssFeed
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &feed))
+	require.Equal(t, "http://example.com/changelog", feed.Channel.Link)
+	require.Len(t, feed.Channel.Items, 3)
+	require.Equal(t, "Version 2.0", feed.Channel.Items[0].Title)
+	require.Equal(t, "Sat, 01 Jun 2024 00:00:00 +0000", feed.Channel.Items[0].PubDate)
+}
+
+func TestFeed_NotConfigured(t *testing.T) {
+	rec := serveFeed(t, NewModule(fstest.MapFS{
+		"README.md": &fstest.MapFile{Data: []byte("# Home\n")},
+	}), "/atom.xml")
+	require.NotContains(t, rec.Header().Get("Content-Type"), "atom")
+}
+
+func TestTruncateText(t *testing.T) {
+	require.Equal(t, "short", truncateText("short", 10))
+	require.Equal(t, "one two…", truncateText("one two three", 10))
+	require.Equal(t, "čez žabe…", truncateText("čez žabe skačejo", 10))
+}
+
+func TestParseFrontmatter_Lenient(t *testing.T) {
+	meta, _, err := parseFrontmatter("---\ntitle: Notes\ntags: go\ndate: \"spring 2024\"\norder: first\n---\n")
+	require.NoError(t, err)
+	require.Equal(t, "Notes", meta.Title)
+	require.Equal(t, []string{"go"}, meta.Tags)
+	require.True(t, meta.Date.IsZero())
+	require.Zero(t, meta.Order)
+	require.Equal(t, []string{`ignoring invalid date "spring 2024"`, `ignoring invalid order "first"`}, meta.Warnings)
+	require.Equal(t, "spring 2024", meta.Page["date"])
+}
+
+func TestFeedPages_SkipsInvalid(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"good.md":  &fstest.MapFile{Data: []byte("---\ntitle: Good\ntags: go\n---\n")},
+		"bad.md":   &fstest.MapFile{Data: []byte("---\ntitle: [\n---\n")},
+		"dated.md": &fstest.MapFile{Data: []byte("---\ntitle: Dated\ndate: spring 2024\n---\n")},
+	})
+	pages, err := m.feedPages()
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+
+	rec := httptest.NewRecorder()
+	require.NoError(t, m.serveSitemap(rec, httptest.NewRequest(http.MethodGet, sitemapURL, nil)))
+	require.Contains(t, rec.Body.String(), "/good</loc>")
+}
+
+func TestFeedPages_Cache(t *testing.T) {
+	content := fstest.MapFS{
+		"a.md": &fstest.MapFile{Data: []byte("# A\n")},
+	}
+	m := NewModule(content)
+
+	pages, err := m.feedPages()
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	pages[0].Title = "changed"
+
+	content["b.md"] = &fstest.MapFile{Data: []byte("# B\n")}
+	pages, err = m.feedPages()
+	require.NoError(t, err)
+	require.Len(t, pages, 1, "cached until the next check")
+	require.Equal(t, "A", pages[0].Title, "callers get a copy")
+
+	m.feedCache.checked = time.Time{}
+	pages, err = m.feedPages()
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+}
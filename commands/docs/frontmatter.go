@@ -0,0 +1,120 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// frontmatterSchemaFile is the JSON Schema page frontmatter is validated
+// against, if it exists in the content root.
+const frontmatterSchemaFile = "frontmatter.schema.json"
+
+// schemaCache holds the compiled frontmatter schema until the schema file
+// changes.
+type schemaCache struct {
+	mu      sync.Mutex
+	schema  *jsonschema.Schema
+	modTime time.Time
+}
+
+// get returns the compiled schema of contentFS, or nil if there is no
+// schema file. The schema is compiled again when the modification time
+// of the file changes.
+func (c *schemaCache) get(contentFS fs.FS) (*jsonschema.Schema, error) {
+	info, err := fs.Stat(contentFS, frontmatterSchemaFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schema != nil && c.modTime.Equal(info.ModTime()) {
+		return c.schema, nil
+	}
+
+	schema, err := compileSchema(contentFS)
+	if err != nil {
+		return nil, err
+	}
+	c.schema, c.modTime = schema, info.ModTime()
+	return schema, nil
+}
+
+// compileSchema compiles the frontmatter schema file of contentFS.
+func compileSchema(contentFS fs.FS) (*jsonschema.Schema, error) {
+	source, err := fs.ReadFile(contentFS, frontmatterSchemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(source))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", frontmatterSchemaFile, err)
+	}
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(frontmatterSchemaFile, schemaDoc); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", frontmatterSchemaFile, err)
+	}
+	schema, err := c.Compile(frontmatterSchemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("compiling %s: %w", frontmatterSchemaFile, err)
+	}
+	return schema, nil
+}
+
+// validateFrontmatter validates frontmatter against the schema file of
+// the docs. Without a schema file, all frontmatter is valid.
+func (m *Module) validateFrontmatter(frontmatter map[string]any) error {
+	schema, err := m.schemaCache.get(m.contentFS)
+	if schema == nil || err != nil {
+		return err
+	}
+
+	// Round-trip through JSON, so YAML values such as dates validate as
+	// the JSON values they represent.
+	encoded, err := json.Marshal(frontmatter)
+	if err != nil {
+		return fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("invalid frontmatter: %w", err)
+	}
+
+	err = schema.Validate(instance)
+	var verr *jsonschema.ValidationError
+	if errors.As(err, &verr) {
+		return fmt.Errorf("invalid frontmatter: %s", schemaErrors(verr))
+	}
+	return err
+}
+
+// schemaErrors summarizes a validation error on one line.
+func schemaErrors(err *jsonschema.ValidationError) string {
+	var messages []string
+	for _, unit := range err.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		location := unit.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", location, unit.Error))
+	}
+	if len(messages) == 0 {
+		return err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
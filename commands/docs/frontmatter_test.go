@@ -0,0 +1,94 @@
+package docs
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const frontmatterSchema = `{
+  "type": "object",
+  "required": ["title"],
+  "properties": {
+    "title": {"type": "string"},
+    "date": {"type": "string", "format": "date-time"},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "order": {"type": "integer"}
+  }
+}`
+
+func TestParseFrontmatter_Page(t *testing.T) {
+	meta, body, err := parseFrontmatter("---\ntitle: Install\ndescription: Getting the CLI.\norder: 2\nredirect_from: [/setup]\naliases: [/get-started]\nhero:\n  image: hero.png\n---\n# Install\n")
+	require.NoError(t, err)
+	require.Equal(t, "# Install", body)
+
+	require.Equal(t, "Getting the CLI.", meta.Description)
+	require.Equal(t, 2, meta.Order)
+	require.Equal(t, []string{"/setup"}, meta.RedirectFrom)
+	require.Equal(t, []string{"/get-started"}, meta.Aliases)
+	require.Equal(t, "Install", meta.Page["title"])
+	require.Equal(t, map[string]any{"image": "hero.png"}, meta.Page["hero"])
+
+	meta, _, err = parseFrontmatter("# No frontmatter\n")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{}, meta.Page)
+}
+
+func TestValidateFrontmatter(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		frontmatterSchemaFile: &fstest.MapFile{Data: []byte(frontmatterSchema)},
+	})
+
+	valid, _, err := parseFrontmatter("---\ntitle: Release\ndate: 2024-03-02\ntags: [a]\norder: 1\n---\n")
+	require.NoError(t, err)
+	require.NoError(t, m.validateFrontmatter(valid.Page))
+
+	err = m.validateFrontmatter(map[string]any{"tags": []any{1}, "order": "first"})
+	require.ErrorContains(t, err, "invalid frontmatter: ")
+	require.ErrorContains(t, err, "title")
+	require.ErrorContains(t, err, "/order: ")
+	require.ErrorContains(t, err, "/tags/0: ")
+}
+
+func TestValidateFrontmatter_NoSchema(t *testing.T) {
+	m := NewModule(fstest.MapFS{})
+	require.NoError(t, m.validateFrontmatter(map[string]any{"order": "first"}))
+}
+
+func TestValidateFrontmatter_BrokenSchema(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		frontmatterSchemaFile: &fstest.MapFile{Data: []byte(`{"type": `)},
+	})
+	require.ErrorContains(t, m.validateFrontmatter(map[string]any{}), "parsing "+frontmatterSchemaFile)
+}
+
+func TestCheck_Frontmatter(t *testing.T) {
+	problems, err := Check(fstest.MapFS{
+		frontmatterSchemaFile: &fstest.MapFile{Data: []byte(frontmatterSchema)},
+		"good.md":             &fstest.MapFile{Data: []byte("---\ntitle: Good\n---\ntext\n")},
+		"bad.md":              &fstest.MapFile{Data: []byte("---\norder: 1\n---\ntext\n")},
+	})
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	require.Equal(t, "bad.md", problems[0].File)
+	require.Equal(t, 1, problems[0].Line)
+	require.Contains(t, problems[0].Message, "invalid frontmatter")
+}
+
+func TestValidateFrontmatter_SchemaCache(t *testing.T) {
+	content := fstest.MapFS{
+		frontmatterSchemaFile: &fstest.MapFile{Data: []byte(frontmatterSchema), ModTime: time.Unix(1, 0)},
+	}
+	m := NewModule(content)
+	require.Error(t, m.validateFrontmatter(map[string]any{}))
+
+	schema := m.schemaCache.schema
+	require.NotNil(t, schema)
+	require.Error(t, m.validateFrontmatter(map[string]any{}))
+	require.Same(t, schema, m.schemaCache.schema, "compiled once")
+
+	content[frontmatterSchemaFile] = &fstest.MapFile{Data: []byte(`{"type": "object"}`), ModTime: time.Unix(2, 0)}
+	require.NoError(t, m.validateFrontmatter(map[string]any{}))
+}
@@ -0,0 +1,95 @@
+package docs
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// codeLexers maps code languages without a chroma lexer to similar ones.
+var codeLexers = map[string]string{
+	"vuego": "html",
+	"less":  "scss",
+}
+
+// codeLexer returns the chroma lexer for a code language, or nil.
+func codeLexer(lang string) chroma.Lexer {
+	lang = strings.ToLower(lang)
+	if alias, ok := codeLexers[lang]; ok {
+		lang = alias
+	}
+	if lang == "" || lang == "text" {
+		return nil
+	}
+	return lexers.Get(lang)
+}
+
+// highlightCode renders code as HTML with chroma token classes, which the
+// basecoat highlight stylesheet maps to the light and dark theme colors.
+// Lines listed in highlight are wrapped in code-line spans like
+// highlightLines does. It reports false and only escapes the code if
+// there is no lexer for lang.
+func highlightCode(code, lang string, highlight []int) (string, bool) {
+	lexer := codeLexer(lang)
+	if lexer == nil {
+		return highlightLines(code, highlight), false
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return highlightLines(code, highlight), false
+	}
+
+	marked := make(map[int]bool, len(highlight))
+	for _, line := range highlight {
+		marked[line] = true
+	}
+
+	var sb strings.Builder
+	for i, line := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if len(highlight) > 0 {
+			class := "code-line"
+			if marked[i+1] {
+				class += " highlighted"
+			}
+			fmt.Fprintf(&sb, `<span class="%s">`, class)
+		}
+
+		for _, token := range line {
+			value := html.EscapeString(strings.TrimSuffix(token.Value, "\n"))
+			if value == "" {
+				continue
+			}
+			if class := tokenClass(token.Type); class != "" {
+				fmt.Fprintf(&sb, `<span class="%s">%s</span>`, class, value)
+			} else {
+				sb.WriteString(value)
+			}
+		}
+
+		if len(highlight) > 0 {
+			sb.WriteString("</span>")
+		}
+	}
+	if strings.HasSuffix(code, "\n") {
+		sb.WriteByte('\n')
+	}
+	return sb.String(), true
+}
+
+// tokenClass returns the chroma class of a token type, falling back to
+// the class of its parent types.
+func tokenClass(t chroma.TokenType) string {
+	for t != 0 {
+		if class, ok := chroma.StandardTypes[t]; ok {
+			return class
+		}
+		t = t.Parent()
+	}
+	return ""
+}
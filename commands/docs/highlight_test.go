@@ -0,0 +1,41 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightCode(t *testing.T) {
+	out, ok := highlightCode("package main\n\n// Main.\nfunc main() {}\n", "go", nil)
+	require.True(t, ok)
+	require.Contains(t, out, `<span class="kn">package</span>`)
+	require.Contains(t, out, `<span class="c1">// Main.</span>`)
+	require.Contains(t, out, `<span class="nf">main</span>`)
+	require.Equal(t, "\n", out[len(out)-1:])
+
+	for _, lang := range []string{"yaml", "json", "html", "vuego", "sh", "bash", "css", "less"} {
+		_, ok := highlightCode("a: 1\n", lang, nil)
+		require.True(t, ok, lang)
+	}
+
+	out, ok = highlightCode(`<div v-if="ok">&</div>`, "vuego", nil)
+	require.True(t, ok)
+	require.Contains(t, out, `<span class="nt">div</span> <span class="na">v-if</span>`)
+	require.NotContains(t, out, "<div")
+}
+
+func TestHighlightCode_Unknown(t *testing.T) {
+	for _, lang := range []string{"", "text", "no-such-language"} {
+		out, ok := highlightCode("a < b", lang, nil)
+		require.False(t, ok, lang)
+		require.Equal(t, "a &lt; b", out)
+	}
+}
+
+func TestHighlightCode_Lines(t *testing.T) {
+	out, ok := highlightCode("a := 1\nb := 2\n", "go", []int{2})
+	require.True(t, ok)
+	require.Contains(t, out, `<span class="code-line"><span class="nx">a</span>`)
+	require.Contains(t, out, "\n"+`<span class="code-line highlighted"><span class="nx">b</span>`)
+}
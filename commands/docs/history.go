@@ -0,0 +1,185 @@
+package docs
+
+import (
+	"bytes"
+	"io/fs"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithContentDir sets the directory the content is read from. Pages in a
+// git repository get their last commit date and author from it, other
+// pages the modification time of their file.
+func WithContentDir(dir string) ModuleOption {
+	return func(m *Module) {
+		m.contentDir = dir
+	}
+}
+
+// WithEditURL sets the "edit this page" link of pages. In pattern, {path}
+// is replaced with the path of the page file in the content directory and
+// {version} with the version of the docs, for example
+// https://github.com/org/repo/edit/main/docs/{path}.
+func WithEditURL(pattern string) ModuleOption {
+	return func(m *Module) {
+		m.editURL = pattern
+	}
+}
+
+// pageHistory is when and by whom a page was last changed.
+type pageHistory struct {
+	Updated time.Time
+	// Author is the author of the last commit, empty if the page is not
+	// in a git repository.
+	Author string
+}
+
+// historyCache holds the git history of pages until their file or the
+// checked out commit changes. The commit is checked at most every
+// searchRefreshInterval, so a new commit may take that long to show.
+type historyCache struct {
+	mu      sync.Mutex
+	entries map[string]historyEntry
+	head    string
+	checked time.Time
+}
+
+type historyEntry struct {
+	head    string
+	modTime time.Time
+	history pageHistory
+}
+
+// currentHead returns the commit checked out in dir, or "" if dir is not
+// in a git repository.
+func (c *historyCache) currentHead(dir string) string {
+	c.mu.Lock()
+	head, fresh := c.head, time.Since(c.checked) < searchRefreshInterval
+	c.mu.Unlock()
+	if fresh {
+		return head
+	}
+
+	head = gitHead(dir)
+
+	c.mu.Lock()
+	c.head, c.checked = head, time.Now()
+	c.mu.Unlock()
+	return head
+}
+
+func (c *historyCache) load(filePath, head string, modTime time.Time) (pageHistory, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[filePath]
+	if !ok || entry.head != head || !entry.modTime.Equal(modTime) {
+		return pageHistory{}, false
+	}
+	return entry.history, true
+}
+
+func (c *historyCache) store(filePath, head string, modTime time.Time, history pageHistory) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]historyEntry)
+	}
+	c.entries[filePath] = historyEntry{head: head, modTime: modTime, history: history}
+}
+
+// sourcePath returns the path of the file a page is read from, which
+// differs from the page path for translations.
+func (m *Module) sourcePath(docPath string) string {
+	if l, ok := m.contentFS.(*localeFS); ok {
+		return l.resolve(docPath)
+	}
+	return docPath
+}
+
+// history returns the last change of a page from git, or the modification
+// time of its file if git has no history for it.
+func (m *Module) history(docPath string) pageHistory {
+	info, err := fs.Stat(m.contentFS, docPath)
+	if err != nil {
+		return pageHistory{}
+	}
+	fallback := pageHistory{Updated: info.ModTime()}
+	if m.contentDir == "" {
+		return fallback
+	}
+
+	filePath := m.sourcePath(docPath)
+	head := m.historyCache.currentHead(m.contentDir)
+	if history, ok := m.historyCache.load(filePath, head, info.ModTime()); ok {
+		return history
+	}
+
+	// git runs without holding the cache lock, so pages render in parallel.
+	history, ok := gitHistory(m.contentDir, filePath)
+	if !ok {
+		history = fallback
+	}
+	m.historyCache.store(filePath, head, info.ModTime(), history)
+	return history
+}
+
+// gitHead returns the commit checked out in the git repository of dir, or
+// "" if there is none.
+func gitHead(dir string) string {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "HEAD")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// gitHistory reads the last commit of filePath in the git repository of
+// dir. It reports false if git is not installed, dir is not in a
+// repository or the file has no commits.
+func gitHistory(dir, filePath string) (pageHistory, bool) {
+	cmd := exec.Command("git", "-C", dir, "log", "-1", "--format=%cI%x00%an", "--", filePath)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return pageHistory{}, false
+	}
+
+	date, author, ok := strings.Cut(strings.TrimSpace(out.String()), "\x00")
+	if !ok {
+		return pageHistory{}, false
+	}
+	updated, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return pageHistory{}, false
+	}
+	return pageHistory{Updated: updated, Author: author}, true
+}
+
+// pageEditURL returns the edit URL of a page, or "" if none is configured.
+func (m *Module) pageEditURL(docPath string) string {
+	if m.editURL == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{path}", m.sourcePath(docPath),
+		"{version}", m.version,
+	).Replace(m.editURL)
+}
+
+// fillHistory sets the updated and edit_url data of a page.
+func (m *Module) fillHistory(data map[string]any, docPath string) {
+	if history := m.history(docPath); !history.Updated.IsZero() {
+		data["updated"] = map[string]any{
+			"date":     history.Updated.Format("2006-01-02"),
+			"datetime": history.Updated.Format(time.RFC3339),
+			"author":   history.Author,
+		}
+	}
+	if editURL := m.pageEditURL(docPath); editURL != "" {
+		data["edit_url"] = editURL
+	}
+}
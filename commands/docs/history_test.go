@@ -0,0 +1,103 @@
+package docs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newGitContent creates a git repository with a committed and an
+// uncommitted page. It returns the directory and a function running git
+// in it as author.
+func newGitContent(t *testing.T) (string, func(author string, args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "guide.md"), []byte("# Guide\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "draft.md"), []byte("# Draft\n"), 0o644))
+
+	git := func(author string, args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author,
+			"GIT_AUTHOR_EMAIL=jane@example.com",
+			"GIT_COMMITTER_NAME=Jane Doe",
+			"GIT_COMMITTER_EMAIL=jane@example.com",
+			"GIT_COMMITTER_DATE=2024-03-02T10:00:00Z",
+			"GIT_CONFIG_GLOBAL=/dev/null",
+		)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("Jane Doe", "init", "-q")
+	git("Jane Doe", "add", "guide.md")
+	git("Jane Doe", "commit", "-q", "-m", "Add guide")
+	return dir, git
+}
+
+func TestHistory_Git(t *testing.T) {
+	dir, git := newGitContent(t)
+	m := NewModule(os.DirFS(dir), WithContentDir(dir))
+
+	history := m.history("guide.md")
+	require.Equal(t, "Jane Doe", history.Author)
+	require.True(t, history.Updated.Equal(time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)))
+
+	// A new commit is picked up, even if the file didn't change.
+	git("John Roe", "commit", "-q", "--amend", "--reset-author", "--no-edit")
+	require.Equal(t, "Jane Doe", m.history("guide.md").Author, "cached until the next check")
+	m.historyCache.checked = time.Time{}
+	require.Equal(t, "John Roe", m.history("guide.md").Author)
+
+	draft := m.history("draft.md")
+	require.Empty(t, draft.Author)
+	info, err := os.Stat(filepath.Join(dir, "draft.md"))
+	require.NoError(t, err)
+	require.True(t, draft.Updated.Equal(info.ModTime()))
+}
+
+func TestHistory_NoRepository(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m := NewModule(fstest.MapFS{
+		"guide.md": &fstest.MapFile{Data: []byte("# Guide\n"), ModTime: modTime},
+	}, WithContentDir(t.TempDir()))
+
+	require.Equal(t, pageHistory{Updated: modTime}, m.history("guide.md"))
+	require.Equal(t, pageHistory{}, m.history("missing.md"))
+
+	data := map[string]any{}
+	m.fillHistory(data, "guide.md")
+	require.Equal(t, map[string]any{
+		"updated": map[string]any{
+			"date":     "2024-05-01",
+			"datetime": "2024-05-01T12:00:00Z",
+			"author":   "",
+		},
+	}, data)
+}
+
+func TestPageEditURL(t *testing.T) {
+	pattern := "https://github.com/org/repo/edit/{version}/docs/{path}"
+
+	m := NewModule(fstest.MapFS{}, WithEditURL(pattern))
+	m.version = "main"
+	require.Equal(t, "https://github.com/org/repo/edit/main/docs/guide/install.md", m.pageEditURL("guide/install.md"))
+
+	lm := NewModule(newLocaleFS(fstest.MapFS{
+		"guide.md":    &fstest.MapFile{Data: []byte("# Guide\n")},
+		"guide.de.md": &fstest.MapFile{Data: []byte("# Anleitung\n")},
+		"intro.md":    &fstest.MapFile{Data: []byte("# Intro\n")},
+	}, []string{"de", "en"}, []string{"en", "de"}), WithEditURL("/edit/{path}"))
+	require.Equal(t, "/edit/guide.de.md", lm.pageEditURL("guide.md"))
+	require.Equal(t, "/edit/intro.md", lm.pageEditURL("intro.md"))
+
+	require.Empty(t, NewModule(fstest.MapFS{}).pageEditURL("guide.md"))
+}
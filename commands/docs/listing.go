@@ -0,0 +1,261 @@
+package docs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// listingLayout renders directory listings. Like other basecoat layouts,
+// it can be overridden from the content tree.
+const listingLayout = "layouts/listing.vuego"
+
+// listingMeta is the listing key of a directory _index file.
+//
+//	listing:
+//	  order: [install.md, usage.md, reference]
+//	  hide: ["*.json", drafts]
+type listingMeta struct {
+	// Order lists entry names shown first, in order. Other entries
+	// follow, directories first, by name.
+	Order []string `yaml:"order"`
+	// Hide lists entry names or glob patterns left out of the listing.
+	Hide []string `yaml:"hide"`
+}
+
+// readListingMeta reads the listing metadata of a directory, if present.
+func readListingMeta(contentFS fs.FS, dir string) (listingMeta, error) {
+	for _, name := range indexFiles {
+		filePath := path.Join(dir, name)
+		content, err := fs.ReadFile(contentFS, filePath)
+		if err != nil {
+			continue
+		}
+
+		source := string(content)
+		if path.Ext(name) == ".md" {
+			source, _ = splitFrontmatter(source)
+		}
+
+		var meta struct {
+			Listing listingMeta `yaml:"listing"`
+		}
+		if err := yaml.Unmarshal([]byte(source), &meta); err != nil {
+			return listingMeta{}, fmt.Errorf("parsing %s: %w", filePath, err)
+		}
+		return meta.Listing, nil
+	}
+	return listingMeta{}, nil
+}
+
+// hidden reports whether the listing hides the entry name. The _index
+// files are always hidden.
+func (l listingMeta) hidden(name string) bool {
+	for _, index := range indexFiles {
+		if name == index {
+			return true
+		}
+	}
+	for _, pattern := range l.Hide {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// listingEntry is a file or directory in a directory listing.
+type listingEntry struct {
+	Name  string
+	Path  string
+	IsDir bool
+	// Title and Subtitle are set from the frontmatter of markdown pages.
+	Title    string
+	Subtitle string
+	// Size and Modified are set for files other than markdown pages.
+	Size     string
+	Modified string
+
+	order int
+}
+
+// data returns the template data of the entry.
+func (e listingEntry) data() map[string]any {
+	return map[string]any{
+		"name":     e.Name,
+		"path":     e.Path,
+		"is_dir":   e.IsDir,
+		"title":    e.Title,
+		"subtitle": e.Subtitle,
+		"size":     e.Size,
+		"modified": e.Modified,
+	}
+}
+
+// breadcrumb is a link to a parent directory of a listing.
+type breadcrumb struct {
+	Label   string
+	URL     string
+	Current bool
+}
+
+// data returns the template data of the breadcrumb.
+func (b breadcrumb) data() map[string]any {
+	return map[string]any{
+		"label":   b.Label,
+		"url":     b.URL,
+		"current": b.Current,
+	}
+}
+
+// listDir returns the entries of dir, ordered and filtered by its _index
+// metadata.
+func (m *Module) listDir(dir string) ([]listingEntry, error) {
+	entries, err := fs.ReadDir(m.contentFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing directory: %w", err)
+	}
+	meta, err := readListingMeta(m.contentFS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	position := make(map[string]int, len(meta.Order))
+	for i, name := range meta.Order {
+		position[strings.TrimSuffix(name, "/")] = i + 1
+	}
+
+	var items []listingEntry
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") || meta.hidden(name) {
+			continue
+		}
+		entryPath := path.Join(dir, name)
+
+		item := listingEntry{
+			Name:  name,
+			Path:  m.url("/" + entryPath),
+			IsDir: e.IsDir(),
+			order: position[name],
+		}
+		switch {
+		case e.IsDir():
+		case path.Ext(name) == ".md":
+			content, err := fs.ReadFile(m.contentFS, entryPath)
+			if err != nil {
+				return nil, err
+			}
+			if pageMeta, _, err := parseFrontmatter(string(content)); err == nil {
+				item.Title = pageMeta.Title
+				item.Subtitle = pageMeta.Subtitle
+			}
+			item.Path = m.url(docURL(entryPath))
+		default:
+			if info, err := e.Info(); err == nil {
+				item.Size = formatSize(info.Size())
+				item.Modified = info.ModTime().Format(time.DateTime)
+			}
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if (a.order == 0) != (b.order == 0) {
+			return a.order != 0
+		}
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		return a.Name < b.Name
+	})
+	return items, nil
+}
+
+// breadcrumbs returns the links from the docs root to dir, labeled with
+// the title of their _index metadata or the directory name.
+func (m *Module) breadcrumbs(dir string) []breadcrumb {
+	label := func(dir, fallback string) string {
+		if meta, err := readIndexMeta(m.contentFS, dir); err == nil && meta.Label != "" {
+			return meta.Label
+		}
+		return fallback
+	}
+
+	crumbs := []breadcrumb{{Label: label(".", "Documentation"), URL: m.url("/")}}
+	if dir != "." {
+		p := ""
+		for _, part := range strings.Split(dir, "/") {
+			p = path.Join(p, part)
+			crumbs = append(crumbs, breadcrumb{Label: label(p, part), URL: m.url("/" + p)})
+		}
+	}
+	crumbs[len(crumbs)-1].Current = true
+	return crumbs
+}
+
+// formatSize formats a file size in bytes for display.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func (m *Module) renderDirListing(ctx context.Context, w http.ResponseWriter, dir string) error {
+	items, err := m.listDir(dir)
+	if err != nil {
+		return err
+	}
+	crumbs := m.breadcrumbs(dir)
+
+	entries := make([]any, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, item.data())
+	}
+	crumbData := make([]any, 0, len(crumbs))
+	for _, crumb := range crumbs {
+		crumbData = append(crumbData, crumb.data())
+	}
+
+	data := map[string]any{
+		"title":       crumbs[len(crumbs)-1].Label,
+		"entries":     entries,
+		"breadcrumbs": crumbData,
+		"path":        dir,
+		"search":      m.url(searchURL),
+	}
+	if m.feedDir != "" {
+		data["feed"] = m.url(atomURL)
+	}
+	m.fill(&data)
+	m.fillLocales(data, docURL(path.Join(dir, "README.md")))
+
+	var buf bytes.Buffer
+	if err := m.vuego.Load(listingLayout).Fill(data).Render(ctx, &buf); err != nil {
+		return fmt.Errorf("rendering layout: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.Copy(w, &buf)
+	return nil
+}
@@ -0,0 +1,83 @@
+package docs
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListDir(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	m := NewModule(fstest.MapFS{
+		"_index.yml":             &fstest.MapFile{Data: []byte("title: Handbook\n")},
+		"guide/_index.yml":       &fstest.MapFile{Data: []byte("title: Guide\nlisting:\n  order: [usage.md, api/]\n  hide: [\"*.json\", drafts]\n")},
+		"guide/install.md":       &fstest.MapFile{Data: []byte("---\ntitle: Install\nsubtitle: Get the CLI.\n---\n")},
+		"guide/usage.md":         &fstest.MapFile{Data: []byte("# Usage\n")},
+		"guide/api/README.md":    &fstest.MapFile{Data: []byte("# API\n")},
+		"guide/assets/logo.svg":  &fstest.MapFile{Data: make([]byte, 2048), ModTime: modTime},
+		"guide/example.go":       &fstest.MapFile{Data: []byte("package main\n"), ModTime: modTime},
+		"guide/schema.json":      &fstest.MapFile{Data: []byte("{}")},
+		"guide/drafts/notes.md":  &fstest.MapFile{Data: []byte("# Notes\n")},
+		"guide/.hidden/notes.md": &fstest.MapFile{Data: []byte("# Notes\n")},
+	})
+	entries, err := m.listDir("guide")
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name)
+	}
+	require.Equal(t, []string{"usage.md", "api", "assets", "example.go", "install.md"}, names)
+
+	require.Equal(t, listingEntry{Name: "usage.md", Path: "/guide/usage", order: 1}, entries[0])
+	require.Equal(t, listingEntry{Name: "api", Path: "/guide/api", IsDir: true, order: 2}, entries[1])
+	require.Equal(t, listingEntry{
+		Name:     "example.go",
+		Path:     "/guide/example.go",
+		Size:     "13 B",
+		Modified: "2024-05-01 12:30:00",
+	}, entries[3])
+	require.Equal(t, map[string]any{
+		"name":     "install.md",
+		"path":     "/guide/install",
+		"is_dir":   false,
+		"title":    "Install",
+		"subtitle": "Get the CLI.",
+		"size":     "",
+		"modified": "",
+	}, entries[4].data())
+}
+
+func TestListDir_Root(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"_index.yml":     &fstest.MapFile{Data: []byte("title: Handbook\n")},
+		"guide/usage.md": &fstest.MapFile{Data: []byte("# Usage\n")},
+	})
+	m.basePath = "/v/2"
+	entries, err := m.listDir(".")
+	require.NoError(t, err)
+	require.Equal(t, []listingEntry{{Name: "guide", Path: "/v/2/guide", IsDir: true}}, entries)
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"_index.yml":            &fstest.MapFile{Data: []byte("title: Handbook\n")},
+		"guide/_index.yml":      &fstest.MapFile{Data: []byte("title: Guide\n")},
+		"guide/assets/logo.svg": &fstest.MapFile{Data: []byte("<svg/>")},
+	})
+	require.Equal(t, []breadcrumb{
+		{Label: "Handbook", URL: "/"},
+		{Label: "Guide", URL: "/guide"},
+		{Label: "assets", URL: "/guide/assets", Current: true},
+	}, m.breadcrumbs("guide/assets"))
+	require.Equal(t, []breadcrumb{{Label: "Documentation", URL: "/", Current: true}}, NewModule(fstest.MapFS{}).breadcrumbs("."))
+	require.Equal(t, map[string]any{"label": "Guide", "url": "/guide", "current": false}, breadcrumb{Label: "Guide", URL: "/guide"}.data())
+}
+
+func TestFormatSize(t *testing.T) {
+	require.Equal(t, "512 B", formatSize(512))
+	require.Equal(t, "2.0 KB", formatSize(2048))
+	require.Equal(t, "1.5 MB", formatSize(3<<19))
+}
@@ -0,0 +1,277 @@
+package docs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/titpetric/platform"
+)
+
+// Locale is a language the docs are written in.
+//
+// Pages of a locale are read from a content directory named after the
+// locale code (de/guide.md) or from files with the locale code before the
+// extension (guide.de.md). Pages and data files that are not translated
+// fall back to the default locale. Locale specific data/*.yml files
+// replace the default ones with the same name.
+type Locale struct {
+	// Code is the language tag used in URLs, file names and hreflang,
+	// for example "de".
+	Code string
+	// Label is shown in the language switcher. It defaults to Code.
+	Label string
+}
+
+// WithLocales serves the default locale at the docs root and every other
+// locale under /{code}/.
+func WithLocales(defaultLocale string, locales ...Locale) ModuleOption {
+	return func(m *Module) {
+		m.defaultLocale = defaultLocale
+		m.locales = locales
+	}
+}
+
+// ParseLocale parses a locale flag value of the form code or code=label.
+func ParseLocale(value string) (Locale, error) {
+	code, label, _ := strings.Cut(value, "=")
+	if code == "" || strings.ContainsAny(code, "/. ") {
+		return Locale{}, fmt.Errorf("invalid locale %q (expected code or code=label)", value)
+	}
+	return Locale{Code: code, Label: label}, nil
+}
+
+// localePath returns the URL prefix of a locale.
+func (m *Module) localePath(code string) string {
+	if code == m.defaultLocale {
+		return ""
+	}
+	return "/" + code
+}
+
+// mountLocales mounts a module per locale, the default locale at the root
+// and the others under their locale code.
+func (m *Module) mountLocales(ctx context.Context, r platform.Router) error {
+	codes := make([]string, 0, len(m.locales))
+	known := false
+	for _, l := range m.locales {
+		codes = append(codes, l.Code)
+		known = known || l.Code == m.defaultLocale
+	}
+	if !known {
+		return fmt.Errorf("default locale %q is not configured", m.defaultLocale)
+	}
+
+	for _, l := range m.locales {
+		chain := []string{l.Code}
+		if l.Code != m.defaultLocale {
+			chain = append(chain, m.defaultLocale)
+		}
+
+		lm := m.derive(newLocaleFS(m.contentFS, chain, codes))
+		lm.locale = l.Code
+		lm.localeRoot = m.basePath
+
+		prefix := m.localePath(l.Code)
+		if prefix == "" {
+			if err := lm.Mount(ctx, r); err != nil {
+				return fmt.Errorf("mounting locale %s: %w", l.Code, err)
+			}
+			continue
+		}
+
+		lm.basePath = m.basePath + prefix
+		router := chi.NewRouter()
+		if err := lm.Mount(ctx, router); err != nil {
+			return fmt.Errorf("mounting locale %s: %w", l.Code, err)
+		}
+		r.Handle(prefix+"/*", http.StripPrefix(prefix, router))
+		r.Get(prefix, redirectTo(lm.basePath+"/"))
+	}
+	return nil
+}
+
+// fillLocales sets the page language and the locales data, with the URL
+// of the page at pageURL in every locale, for hreflang links and the
+// language switcher.
+func (m *Module) fillLocales(data map[string]any, pageURL string) {
+	if m.locale == "" {
+		if _, ok := data["lang"]; !ok {
+			data["lang"] = "en"
+		}
+		return
+	}
+
+	var locales []any
+	for _, l := range m.locales {
+		label := l.Label
+		if label == "" {
+			label = l.Code
+		}
+		locales = append(locales, map[string]any{
+			"code":    l.Code,
+			"label":   label,
+			"url":     m.localeRoot + m.localePath(l.Code) + pageURL,
+			"current": l.Code == m.locale,
+		})
+	}
+
+	data["lang"] = m.locale
+	data["locales"] = locales
+}
+
+// localeFS presents the content of one locale. Files resolve to the first
+// translation found along the locale chain, then to the untranslated file.
+// Locale directories and suffixed translations are hidden.
+type localeFS struct {
+	fs.FS
+
+	// chain holds the locale and the locales it falls back to.
+	chain []string
+	// codes holds all configured locale codes.
+	codes map[string]bool
+}
+
+func newLocaleFS(contentFS fs.FS, chain, codes []string) *localeFS {
+	l := &localeFS{
+		FS:    contentFS,
+		chain: chain,
+		codes: make(map[string]bool, len(codes)),
+	}
+	for _, code := range codes {
+		l.codes[code] = true
+	}
+	return l
+}
+
+// localized reports whether name is inside a locale directory or is a
+// suffixed translation.
+func (l *localeFS) localized(name string) bool {
+	first, _, _ := strings.Cut(name, "/")
+	if l.codes[first] {
+		return true
+	}
+
+	base := path.Base(name)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	if code := path.Ext(stem); code != "" {
+		return l.codes[code[1:]]
+	}
+	return false
+}
+
+// candidates returns the files name may resolve to, in order.
+func (l *localeFS) candidates(name string) []string {
+	if name == "." {
+		return []string{name}
+	}
+
+	var result []string
+	ext := path.Ext(name)
+	for _, code := range l.chain {
+		result = append(result, path.Join(code, name))
+		if ext != "" {
+			result = append(result, strings.TrimSuffix(name, ext)+"."+code+ext)
+		}
+	}
+	return append(result, name)
+}
+
+// resolve returns the file name resolves to, or name if none exists.
+func (l *localeFS) resolve(name string) string {
+	if l.localized(name) {
+		return name
+	}
+	for _, candidate := range l.candidates(name) {
+		if info, err := fs.Stat(l.FS, candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return name
+}
+
+// Open opens the translation of name. Directories resolve to the last
+// directory found, which is the untranslated one if it exists.
+func (l *localeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if l.localized(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var dir fs.File
+	for _, candidate := range l.candidates(name) {
+		f, err := l.FS.Open(candidate)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if info, err := f.Stat(); err == nil && info.IsDir() {
+			if dir != nil {
+				dir.Close()
+			}
+			dir = f
+			continue
+		}
+		if dir != nil {
+			dir.Close()
+		}
+		return f, nil
+	}
+
+	if dir != nil {
+		return dir, nil
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists a directory merged with its locale directories, so pages
+// that only exist in a locale directory are listed too.
+func (l *localeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if l.localized(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	dirs := make([]string, 0, len(l.chain)+1)
+	for _, code := range l.chain {
+		dirs = append(dirs, path.Join(code, name))
+	}
+	dirs = append(dirs, name)
+
+	found := false
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for _, dir := range dirs {
+		list, err := fs.ReadDir(l.FS, dir)
+		if err != nil {
+			continue
+		}
+		found = true
+
+		for _, e := range list {
+			if seen[e.Name()] || l.localized(path.Join(name, e.Name())) {
+				continue
+			}
+			seen[e.Name()] = true
+			entries = append(entries, e)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
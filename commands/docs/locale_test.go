@@ -0,0 +1,153 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func readString(t *testing.T, fsys fs.FS, name string) string {
+	t.Helper()
+	content, err := fs.ReadFile(fsys, name)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestLocaleFS_Open(t *testing.T) {
+	content := fstest.MapFS{
+		"README.md":          &fstest.MapFile{Data: []byte("# Welcome\n")},
+		"guide.md":           &fstest.MapFile{Data: []byte("# Guide\n\nInstall the tools.\n")},
+		"guide.de.md":        &fstest.MapFile{Data: []byte("# Anleitung\n\nInstalliere die Werkzeuge.\n")},
+		"faq.md":             &fstest.MapFile{Data: []byte("# FAQ\n")},
+		"de/impressum.md":    &fstest.MapFile{Data: []byte("# Impressum\n")},
+		"data/site.yml":      &fstest.MapFile{Data: []byte("tagline: Hello\n")},
+		"data/site.de.yml":   &fstest.MapFile{Data: []byte("tagline: Hallo\n")},
+		"api/reference.md":   &fstest.MapFile{Data: []byte("# Reference\n")},
+		"de/api/overview.md": &fstest.MapFile{Data: []byte("# Übersicht\n")},
+	}
+	codes := []string{"en", "de"}
+	en := newLocaleFS(content, []string{"en"}, codes)
+	de := newLocaleFS(content, []string{"de", "en"}, codes)
+
+	require.Contains(t, readString(t, en, "guide.md"), "# Guide")
+	require.Contains(t, readString(t, de, "guide.md"), "# Anleitung")
+	require.Contains(t, readString(t, de, "faq.md"), "# FAQ", "untranslated pages fall back")
+	require.Contains(t, readString(t, de, "impressum.md"), "# Impressum")
+	require.Equal(t, "tagline: Hallo\n", readString(t, de, "data/site.yml"))
+	require.Equal(t, "tagline: Hello\n", readString(t, en, "data/site.yml"))
+
+	for _, name := range []string{"guide.de.md", "de/impressum.md", "impressum.md"} {
+		_, err := fs.Stat(en, name)
+		require.ErrorIs(t, err, fs.ErrNotExist, name)
+	}
+	_, err := fs.Stat(de, "guide.de.md")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	info, err := fs.Stat(de, "api")
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+}
+
+func TestLocaleFS_ReadDir(t *testing.T) {
+	content := fstest.MapFS{
+		"README.md":          &fstest.MapFile{Data: []byte("# Welcome\n")},
+		"guide.md":           &fstest.MapFile{Data: []byte("# Guide\n\nInstall the tools.\n")},
+		"guide.de.md":        &fstest.MapFile{Data: []byte("# Anleitung\n\nInstalliere die Werkzeuge.\n")},
+		"faq.md":             &fstest.MapFile{Data: []byte("# FAQ\n")},
+		"de/impressum.md":    &fstest.MapFile{Data: []byte("# Impressum\n")},
+		"data/site.yml":      &fstest.MapFile{Data: []byte("tagline: Hello\n")},
+		"data/site.de.yml":   &fstest.MapFile{Data: []byte("tagline: Hallo\n")},
+		"api/reference.md":   &fstest.MapFile{Data: []byte("# Reference\n")},
+		"de/api/overview.md": &fstest.MapFile{Data: []byte("# Übersicht\n")},
+	}
+	codes := []string{"en", "de"}
+	en := newLocaleFS(content, []string{"en"}, codes)
+	de := newLocaleFS(content, []string{"de", "en"}, codes)
+
+	names := func(fsys fs.FS, dir string) []string {
+		entries, err := fs.ReadDir(fsys, dir)
+		require.NoError(t, err)
+		var result []string
+		for _, e := range entries {
+			result = append(result, e.Name())
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"README.md", "api", "data", "faq.md", "guide.md"}, names(en, "."))
+	require.Equal(t, []string{"README.md", "api", "data", "faq.md", "guide.md", "impressum.md"}, names(de, "."))
+	require.Equal(t, []string{"overview.md", "reference.md"}, names(de, "api"))
+	require.Equal(t, []string{"site.yml"}, names(de, "data"))
+}
+
+func TestLocales_Mount(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"README.md":   &fstest.MapFile{Data: []byte("# Welcome\n")},
+		"guide.md":    &fstest.MapFile{Data: []byte("# Guide\n\nInstall the tools.\n")},
+		"guide.de.md": &fstest.MapFile{Data: []byte("# Anleitung\n\nInstalliere die Werkzeuge.\n")},
+	}, WithLocales("en", Locale{Code: "en", Label: "English"}, Locale{Code: "de", Label: "Deutsch"}))
+
+	r := chi.NewRouter()
+	require.NoError(t, m.Mount(context.Background(), r))
+
+	search := func(target string) []SearchResult {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, rec.Code, target)
+
+		var resp struct {
+			Results []SearchResult `json:"results"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		return resp.Results
+	}
+
+	results := search("/de/search?q=werkzeuge")
+	require.Len(t, results, 1)
+	require.Equal(t, "/de/guide", results[0].URL)
+	require.Equal(t, "Anleitung", results[0].Title)
+
+	require.Empty(t, search("/search?q=werkzeuge"))
+	require.Len(t, search("/search?q=tools"), 1)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/de", nil))
+	require.Equal(t, "/de/", rec.Header().Get("Location"))
+}
+
+func TestLocales_Data(t *testing.T) {
+	m := NewModule(fstest.MapFS{}, WithLocales("en", Locale{Code: "en"}, Locale{Code: "de", Label: "Deutsch"}))
+	m.locale = "de"
+	m.localeRoot = "/v/v2"
+
+	data := map[string]any{}
+	m.fillLocales(data, "/guide")
+
+	require.Equal(t, "de", data["lang"])
+	require.Equal(t, []any{
+		map[string]any{"code": "en", "label": "en", "url": "/v/v2/guide", "current": false},
+		map[string]any{"code": "de", "label": "Deutsch", "url": "/v/v2/de/guide", "current": true},
+	}, data["locales"])
+
+	data = map[string]any{}
+	NewModule(fstest.MapFS{}).fillLocales(data, "/guide")
+	require.Equal(t, map[string]any{"lang": "en"}, data)
+}
+
+func TestParseLocale(t *testing.T) {
+	l, err := ParseLocale("de=Deutsch")
+	require.NoError(t, err)
+	require.Equal(t, Locale{Code: "de", Label: "Deutsch"}, l)
+
+	for _, value := range []string{"", "=Deutsch", "de/at", "de.md"} {
+		_, err := ParseLocale(value)
+		require.Error(t, err, value)
+	}
+}
@@ -0,0 +1,299 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	mdhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// newMarkdown returns a GitHub flavored markdown converter with tables,
+// task lists, strikethrough, autolinks, footnotes and admonitions. Raw
+// HTML is passed through. Headings, code, diagrams and math are rendered
+// by r.
+func newMarkdown(r *customRenderer) goldmark.Markdown {
+	return goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(
+			parser.WithAttribute(),
+			parser.WithASTTransformers(util.Prioritized(admonitionTransformer{}, 100)),
+		),
+		goldmark.WithRendererOptions(
+			mdhtml.WithUnsafe(),
+			renderer.WithNodeRenderers(util.Prioritized(r, 100)),
+		),
+	)
+}
+
+func renderMarkdown(in string) string {
+	return renderMarkdownHeadings(in, newHeadingIDs())
+}
+
+// renderMarkdownHeadings renders markdown, assigning heading IDs from headings.
+func renderMarkdownHeadings(in string, headings *headingIDs) string {
+	var buf bytes.Buffer
+	_ = newMarkdown(&customRenderer{headings: headings}).Convert([]byte(in), &buf)
+	return buf.String()
+}
+
+// renderMarkdown renders markdown of the page, requiring the scripts of
+// the diagrams and math it contains.
+func (p *pageParser) renderMarkdown(in string) string {
+	var buf bytes.Buffer
+	_ = newMarkdown(&customRenderer{headings: p.page.headings, require: p.require}).Convert([]byte(in), &buf)
+	return buf.String()
+}
+
+// customRenderer renders headings with anchors, code highlighted with the
+// docs code styles, mermaid diagrams, math and admonitions as basecoat
+// alerts.
+type customRenderer struct {
+	headings *headingIDs
+	// require adds a partial with the scripts rendered content needs to
+	// the page. It is nil when rendering outside of a page.
+	require func(partial string)
+}
+
+// codeRenderers are partials rendering fenced code blocks of a language
+// in the browser. The block content is kept as text in an element with
+// the class the partial looks for.
+var codeRenderers = map[string]struct {
+	partial string
+	tag     string
+	class   string
+}{
+	"mermaid": {partial: "partials/mermaid.vuego", tag: "pre", class: "mermaid"},
+	"math":    {partial: "partials/math.vuego", tag: "div", class: "math math-display"},
+	"latex":   {partial: "partials/math.vuego", tag: "div", class: "math math-display"},
+}
+
+// RegisterFuncs implements renderer.NodeRenderer.
+func (r *customRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindHeading, r.renderHeading)
+	reg.Register(ast.KindFencedCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindCodeBlock, r.renderCodeBlock)
+	reg.Register(ast.KindCodeSpan, r.renderCodeSpan)
+	reg.Register(kindAdmonition, r.renderAdmonition)
+}
+
+func (r *customRenderer) renderHeading(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	n := node.(*ast.Heading)
+	if entering {
+		var explicit string
+		if id, ok := n.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				explicit = string(b)
+			}
+		}
+		id := r.headings.add(n.Level, nodeText(n, source), explicit)
+		n.SetAttributeString("id", []byte(id))
+		fmt.Fprintf(w, `<h%d id="%s">`, n.Level, html.EscapeString(id))
+		return ast.WalkContinue, nil
+	}
+
+	id, _ := n.AttributeString("id")
+	fmt.Fprintf(w, `<a class="heading-anchor" href="#%s" aria-label="Link to this section">#</a></h%d>`+"\n", html.EscapeString(string(id.([]byte))), n.Level)
+	return ast.WalkContinue, nil
+}
+
+func (r *customRenderer) renderCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	lang := "text"
+	if n, ok := node.(*ast.FencedCodeBlock); ok {
+		if l := n.Language(source); len(l) > 0 {
+			lang = string(l)
+		}
+	}
+
+	var code bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	if cr, ok := codeRenderers[lang]; ok {
+		if r.require != nil {
+			r.require(cr.partial)
+		}
+		fmt.Fprintf(w, `<%s class="%s my-6">%s</%s>`+"\n", cr.tag, cr.class, html.EscapeString(code.String()), cr.tag)
+		return ast.WalkSkipChildren, nil
+	}
+
+	highlighted, ok := highlightCode(code.String(), lang, nil)
+	class := "language-" + html.EscapeString(lang)
+	if ok {
+		class += " chroma"
+	}
+
+	fmt.Fprintf(w, `<pre class="grid text-sm max-h-[650px] overflow-y-auto rounded-xl scrollbar"><code class="%s !bg-muted/40 !p-3.5">`, class)
+	w.WriteString(highlighted)
+	w.WriteString("</code></pre>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *customRenderer) renderCodeSpan(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	var sb strings.Builder
+	for c := node.FirstChild(); c != nil; c = c.NextSibling() {
+		switch t := c.(type) {
+		case *ast.Text:
+			value := t.Value(source)
+			if bytes.HasSuffix(value, []byte("\n")) {
+				sb.Write(value[:len(value)-1])
+				sb.WriteByte(' ')
+				continue
+			}
+			sb.Write(value)
+		case *ast.String:
+			sb.Write(t.Value)
+		}
+	}
+
+	code := sb.String()
+	if strings.HasPrefix(code, "<") {
+		w.WriteString(`<code class="highlight language-html">`)
+	} else {
+		w.WriteString(`<code class="highlight">`)
+	}
+	w.WriteString(html.EscapeString(code))
+	w.WriteString("</code>")
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *customRenderer) renderAdmonition(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		w.WriteString("</section></div>\n")
+		return ast.WalkContinue, nil
+	}
+
+	style := admonitionStyles[node.(*admonition).kind]
+	fmt.Fprintf(w, `<div role="alert" class="%s my-6"><i data-lucide="%s"></i><h2>%s</h2><section>`, style.class, style.icon, style.title)
+	return ast.WalkContinue, nil
+}
+
+// nodeText returns the text content of a node and its children.
+func nodeText(node ast.Node, source []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := n.(type) {
+		case *ast.Text:
+			sb.Write(t.Value(source))
+			if t.SoftLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// kindAdmonition is the node kind of admonitions.
+var kindAdmonition = ast.NewNodeKind("Admonition")
+
+// admonition is a GitHub alert, a blockquote starting with a marker line:
+//
+//	> [!NOTE]
+//	> Text of the note.
+type admonition struct {
+	ast.BaseBlock
+
+	kind string
+}
+
+// Kind implements ast.Node.
+func (n *admonition) Kind() ast.NodeKind {
+	return kindAdmonition
+}
+
+// Dump implements ast.Node.
+func (n *admonition) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Kind": n.kind}, nil)
+}
+
+// admonitionStyles maps admonition kinds to basecoat alert styles.
+var admonitionStyles = map[string]struct {
+	title string
+	class string
+	icon  string
+}{
+	"NOTE":      {title: "Note", class: "alert", icon: "info"},
+	"TIP":       {title: "Tip", class: "alert", icon: "lightbulb"},
+	"IMPORTANT": {title: "Important", class: "alert", icon: "message-square-warning"},
+	"WARNING":   {title: "Warning", class: "alert-destructive", icon: "triangle-alert"},
+	"CAUTION":   {title: "Caution", class: "alert-destructive", icon: "octagon-alert"},
+}
+
+var admonitionPattern = regexp.MustCompile(`^(?i)\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)\]$`)
+
+// admonitionTransformer replaces blockquotes starting with an admonition
+// marker line with admonition nodes.
+type admonitionTransformer struct{}
+
+// Transform implements parser.ASTTransformer.
+func (admonitionTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	source := reader.Source()
+
+	var quotes []*ast.Blockquote
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if q, ok := n.(*ast.Blockquote); ok && entering {
+			quotes = append(quotes, q)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, q := range quotes {
+		para, ok := q.FirstChild().(*ast.Paragraph)
+		if !ok || para.Lines().Len() == 0 {
+			continue
+		}
+		marker := para.Lines().At(0)
+		match := admonitionPattern.FindSubmatch(bytes.TrimSpace(marker.Value(source)))
+		if match == nil {
+			continue
+		}
+
+		// Drop the inline nodes of the marker line.
+		for c := para.FirstChild(); c != nil; {
+			t, ok := c.(*ast.Text)
+			if !ok || t.Segment.Start >= marker.Stop {
+				break
+			}
+			next := c.NextSibling()
+			para.RemoveChild(para, c)
+			c = next
+		}
+		if para.ChildCount() == 0 {
+			q.RemoveChild(q, para)
+		}
+
+		a := &admonition{kind: strings.ToUpper(string(match[1]))}
+		for c := q.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			a.AppendChild(a, c)
+			c = next
+		}
+		q.Parent().ReplaceChild(q.Parent(), q, a)
+	}
+}
@@ -0,0 +1,82 @@
+package docs
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderMarkdown_GFM(t *testing.T) {
+	out := renderMarkdown(`| Name | Type |
+| ---- | ---- |
+| id   | int  |
+
+- [x] done
+- [ ] todo
+
+~~removed~~ and https://example.com
+
+Text with a note.[^1]
+
+[^1]: The footnote.
+`)
+
+	require.Contains(t, out, "<table>")
+	require.Contains(t, out, "<td>id</td>")
+	require.Contains(t, out, `<input checked="" disabled="" type="checkbox"`)
+	require.Contains(t, out, "<del>removed</del>")
+	require.Contains(t, out, `<a href="https://example.com">https://example.com</a>`)
+	require.Contains(t, out, `class="footnotes"`)
+}
+
+func TestRenderMarkdown_Code(t *testing.T) {
+	out := renderMarkdown("Use `<div>` and `v-if`.\n\n```go\nfmt.Println(\"<hi>\")\n```\n\n    indented\n")
+
+	require.Contains(t, out, `<code class="highlight language-html">&lt;div&gt;</code>`)
+	require.Contains(t, out, `<code class="highlight">v-if</code>`)
+	require.Contains(t, out, `<code class="language-go chroma !bg-muted/40 !p-3.5"><span class="nx">fmt</span>`)
+	require.Contains(t, out, `<span class="s">&#34;&lt;hi&gt;&#34;</span><span class="p">)</span>`+"\n</code>")
+	require.Contains(t, out, `<code class="language-text !bg-muted/40 !p-3.5">indented`)
+}
+
+func TestRenderMarkdown_RawHTML(t *testing.T) {
+	out := renderMarkdown("<div class=\"grid\">\n\n**bold**\n\n</div>\n")
+	require.Contains(t, out, `<div class="grid">`)
+	require.Contains(t, out, "<strong>bold</strong>")
+}
+
+func TestRenderMarkdown_ExplicitHeadingID(t *testing.T) {
+	out := renderMarkdown("## Getting started {#start}\n")
+	require.Contains(t, out, `<h2 id="start">Getting started<a class="heading-anchor" href="#start"`)
+}
+
+func TestRenderMarkdown_Admonition(t *testing.T) {
+	out := renderMarkdown("> [!WARNING]\n> Back up your **data** first.\n>\n> Second paragraph.\n\n> [!tip]\n> Lower case works too.\n\n> A plain [!NOTE] quote.\n")
+
+	require.Contains(t, out, `<div role="alert" class="alert-destructive my-6"><i data-lucide="triangle-alert"></i><h2>Warning</h2><section><p>Back up your <strong>data</strong> first.</p>`)
+	require.Contains(t, out, "<p>Second paragraph.</p>\n</section></div>")
+	require.Contains(t, out, `<h2>Tip</h2><section><p>Lower case works too.</p>`)
+	require.NotContains(t, out, "[!WARNING]")
+	require.Contains(t, out, "<blockquote>\n<p>A plain [!NOTE] quote.</p>")
+}
+
+func TestRenderMarkdown_DiagramsAndMath(t *testing.T) {
+	out := renderMarkdown("```mermaid\ngraph TD\n  A --> B\n```\n\n```math\na < b\n```\n")
+	require.Contains(t, out, "<pre class=\"mermaid my-6\">graph TD\n  A --&gt; B\n</pre>")
+	require.Contains(t, out, "<div class=\"math math-display my-6\">a &lt; b\n</div>")
+}
+
+func TestParseDirectives_RequiresDiagramScripts(t *testing.T) {
+	m := NewModule(fstest.MapFS{})
+
+	doc, err := m.parseDirectives(context.Background(), "page.md", "# Page\n\n```go\nx := 1\n```\n", 0)
+	require.NoError(t, err)
+	require.Empty(t, doc.Partials)
+
+	body := "```mermaid\ngraph TD\n```\n\n```mermaid\ngraph LR\n```\n\n```latex\nE = mc^2\n```\n"
+	doc, err = m.parseDirectives(context.Background(), "page.md", body, 0)
+	require.NoError(t, err)
+	require.Equal(t, []string{"partials/mermaid.vuego", "partials/math.vuego"}, doc.Partials)
+}
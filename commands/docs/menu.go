@@ -0,0 +1,442 @@
+package docs
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// MenuMode controls how the sidebar menu is built.
+type MenuMode string
+
+const (
+	// MenuMerge generates the menu from the docs tree and merges the
+	// manual data/menu.yml into it. Manual groups and items override
+	// generated ones with the same label or URL.
+	MenuMerge MenuMode = "merge"
+	// MenuAuto uses only the menu generated from the docs tree.
+	MenuAuto MenuMode = "auto"
+	// MenuManual uses only the manual data/menu.yml.
+	MenuManual MenuMode = "manual"
+)
+
+// ParseMenuMode parses a menu mode name.
+func ParseMenuMode(s string) (MenuMode, error) {
+	switch mode := MenuMode(s); mode {
+	case MenuMerge, MenuAuto, MenuManual:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown menu mode %q (expected merge, auto or manual)", s)
+}
+
+// indexFiles hold per-directory metadata: the group title, order, icon and
+// whether the directory is hidden from the menu.
+var indexFiles = []string{"_index.yml", "_index.yaml", "_index.md"}
+
+// menuEntry is a page or directory considered for the generated menu.
+type menuEntry struct {
+	Label  string
+	URL    string
+	Icon   string
+	Order  int
+	Hidden bool
+}
+
+// sortMenuEntries sorts entries by order, then label.
+func sortMenuEntries(entries []menuEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Order != entries[j].Order {
+			return entries[i].Order < entries[j].Order
+		}
+		return entries[i].Label < entries[j].Label
+	})
+}
+
+// buildMenu generates sidebar menu groups from the markdown files of
+// contentFS. Root pages form the first group, every top-level directory
+// forms a group of the pages below it. Groups and pages are sorted by
+// their `order` (or `weight`) and title.
+func buildMenu(contentFS fs.FS) ([]any, error) {
+	type group struct {
+		menuEntry
+		items []menuEntry
+	}
+
+	rootMeta, err := readIndexMeta(contentFS, ".")
+	if err != nil {
+		return nil, err
+	}
+	root := &group{menuEntry: rootMeta}
+	if root.Label == "" {
+		root.Label = "Documentation"
+	}
+
+	groups := map[string]*group{}
+	err = walkMarkdown(contentFS, func(filePath string) error {
+		if path.Base(filePath) == "_index.md" {
+			return nil
+		}
+
+		entry, err := readPageMeta(contentFS, filePath)
+		if err != nil {
+			log.Printf("menu: skipping %s: %v", filePath, err)
+			return nil
+		}
+
+		dir, _, nested := strings.Cut(filePath, "/")
+		if !nested {
+			root.items = append(root.items, entry)
+			return nil
+		}
+
+		g, ok := groups[dir]
+		if !ok {
+			meta, err := readIndexMeta(contentFS, dir)
+			if err != nil {
+				return err
+			}
+			if meta.Label == "" {
+				meta.Label = dir
+			}
+			g = &group{menuEntry: meta}
+			groups[dir] = g
+		}
+		if !hiddenPath(contentFS, filePath) {
+			g.items = append(g.items, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := []*group{root}
+	var rest []menuEntry
+	for dir, g := range groups {
+		rest = append(rest, menuEntry{Label: g.Label, URL: dir, Order: g.Order, Hidden: g.Hidden})
+	}
+	sortMenuEntries(rest)
+	for _, e := range rest {
+		if !e.Hidden {
+			ordered = append(ordered, groups[e.URL])
+		}
+	}
+
+	var menu []any
+	for _, g := range ordered {
+		sortMenuEntries(g.items)
+
+		var items []any
+		for _, item := range g.items {
+			if item.Hidden {
+				continue
+			}
+			entry := map[string]any{
+				"label": item.Label,
+				"url":   item.URL,
+			}
+			if item.Icon != "" {
+				entry["icon"] = item.Icon
+			}
+			items = append(items, entry)
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		menu = append(menu, map[string]any{
+			"type":  "group",
+			"label": g.Label,
+			"items": items,
+		})
+	}
+	return menu, nil
+}
+
+// readPageMeta reads the menu entry of a markdown page.
+func readPageMeta(contentFS fs.FS, filePath string) (menuEntry, error) {
+	content, err := fs.ReadFile(contentFS, filePath)
+	if err != nil {
+		return menuEntry{}, err
+	}
+
+	meta, body, err := parseFrontmatter(string(content))
+	if err != nil {
+		return menuEntry{}, fmt.Errorf("parsing %s: %w", filePath, err)
+	}
+	for _, warning := range meta.Warnings {
+		log.Printf("menu: %s: %s", filePath, warning)
+	}
+
+	entry := menuEntry{
+		Label:  meta.Title,
+		Order:  meta.Order,
+		Icon:   meta.Icon,
+		Hidden: meta.Hidden,
+	}
+	if _, ok := meta.Page["order"]; !ok {
+		entry.Order = meta.Weight
+	}
+
+	if entry.Label == "" {
+		if headings := markdownHeadings(body); len(headings) > 0 {
+			entry.Label = headings[0]
+		} else {
+			entry.Label = strings.TrimSuffix(path.Base(filePath), ".md")
+		}
+	}
+	entry.URL = docURL(filePath)
+	return entry, nil
+}
+
+// readIndexMeta reads the _index metadata of a directory, if present.
+func readIndexMeta(contentFS fs.FS, dir string) (menuEntry, error) {
+	for _, name := range indexFiles {
+		filePath := path.Join(dir, name)
+		content, err := fs.ReadFile(contentFS, filePath)
+		if err != nil {
+			continue
+		}
+
+		source := string(content)
+		if path.Ext(name) == ".md" {
+			source, _ = splitFrontmatter(source)
+		}
+
+		entry, err := parseMenuMeta(source)
+		if err != nil {
+			return menuEntry{}, fmt.Errorf("parsing %s: %w", filePath, err)
+		}
+		return entry, nil
+	}
+	return menuEntry{}, nil
+}
+
+// hiddenPath reports whether a directory between the top-level group and
+// filePath is hidden through its _index metadata.
+func hiddenPath(contentFS fs.FS, filePath string) bool {
+	dir := path.Dir(filePath)
+	for strings.Contains(dir, "/") {
+		if meta, err := readIndexMeta(contentFS, dir); err == nil && meta.Hidden {
+			return true
+		}
+		dir = path.Dir(dir)
+	}
+	return false
+}
+
+// parseMenuMeta reads the title, order (or weight), icon and hidden keys
+// from the YAML metadata of a directory.
+func parseMenuMeta(source string) (menuEntry, error) {
+	var meta struct {
+		Title  string `yaml:"title"`
+		Order  *int   `yaml:"order"`
+		Weight *int   `yaml:"weight"`
+		Icon   string `yaml:"icon"`
+		Hidden bool   `yaml:"hidden"`
+	}
+	if err := yaml.Unmarshal([]byte(source), &meta); err != nil {
+		return menuEntry{}, err
+	}
+
+	entry := menuEntry{
+		Label:  meta.Title,
+		Icon:   meta.Icon,
+		Hidden: meta.Hidden,
+	}
+	switch {
+	case meta.Order != nil:
+		entry.Order = *meta.Order
+	case meta.Weight != nil:
+		entry.Order = *meta.Weight
+	}
+	return entry, nil
+}
+
+// mergeMenu merges manual menu groups into generated ones. A manual group
+// with the label of a generated group extends it: manual items replace
+// generated items with the same URL and other manual items are appended.
+// Manual groups without a generated counterpart are appended.
+func mergeMenu(generated, manual []any) []any {
+	merged := make([]any, 0, len(generated)+len(manual))
+	byLabel := map[any]map[string]any{}
+	for _, g := range generated {
+		group, ok := g.(map[string]any)
+		if !ok {
+			continue
+		}
+		byLabel[group["label"]] = group
+		merged = append(merged, group)
+	}
+
+	for _, g := range manual {
+		group, ok := g.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		target, exists := byLabel[group["label"]]
+		if !exists {
+			merged = append(merged, group)
+			continue
+		}
+
+		items, _ := target["items"].([]any)
+		manualItems, _ := group["items"].([]any)
+		target["items"] = mergeMenuItems(items, manualItems)
+		for key, value := range group {
+			if key != "items" {
+				target[key] = value
+			}
+		}
+	}
+	return merged
+}
+
+func mergeMenuItems(items, manual []any) []any {
+	byURL := map[any]int{}
+	for i, item := range items {
+		if entry, ok := item.(map[string]any); ok {
+			byURL[entry["url"]] = i
+		}
+	}
+
+	for _, item := range manual {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if i, exists := byURL[entry["url"]]; exists {
+			for key, value := range entry {
+				items[i].(map[string]any)[key] = value
+			}
+			continue
+		}
+		items = append(items, entry)
+	}
+	return items
+}
+
+// fillMenu sets the menu data according to the module menu mode.
+func (m *Module) fillMenu(dest *map[string]any) {
+	if m.menuMode == MenuManual {
+		return
+	}
+
+	generated, ok := m.menuCache.get(m.contentFS)
+	if !ok {
+		return
+	}
+
+	if *dest == nil {
+		*dest = map[string]any{}
+	}
+
+	if m.menuMode == MenuAuto {
+		(*dest)["menu"] = generated
+		return
+	}
+
+	manual, _ := (*dest)["menu"].([]any)
+	(*dest)["menu"] = mergeMenu(generated, manual)
+}
+
+// menuCache holds the generated menu until the markdown or _index files
+// change, checked at most every searchRefreshInterval.
+type menuCache struct {
+	mu        sync.Mutex
+	menu      []any
+	built     bool
+	signature string
+	checked   time.Time
+}
+
+// get returns a copy of the generated menu of contentFS, rebuilding it if
+// the content changed. A failed build is logged and the previous menu is
+// kept; ok is false if no menu was built yet.
+func (c *menuCache) get(contentFS fs.FS) ([]any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.built || time.Since(c.checked) >= searchRefreshInterval {
+		c.checked = time.Now()
+		signature, err := menuSignature(contentFS)
+		if err != nil {
+			log.Printf("menu: checking for changes: %v", err)
+		} else if !c.built || signature != c.signature {
+			menu, err := buildMenu(contentFS)
+			if err != nil {
+				log.Printf("menu: %v", err)
+			} else {
+				c.menu, c.built, c.signature = menu, true, signature
+			}
+		}
+	}
+
+	if !c.built {
+		return nil, false
+	}
+	// Callers merge into and prefix the menu in place.
+	return copyMenu(c.menu), true
+}
+
+// menuSignature summarizes the files the menu is built from, like
+// markdownSignature, including the _index files.
+func menuSignature(contentFS fs.FS) (string, error) {
+	var sb strings.Builder
+	err := fs.WalkDir(contentFS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || (path.Ext(p) != ".md" && !slices.Contains(indexFiles, d.Name())) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d;", p, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return sb.String(), err
+}
+
+// copyMenu returns a deep copy of menu data.
+func copyMenu(menu []any) []any {
+	if menu == nil {
+		return nil
+	}
+	out := make([]any, len(menu))
+	for i, item := range menu {
+		out[i] = copyMenuValue(item)
+	}
+	return out
+}
+
+func copyMenuValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = copyMenuValue(item)
+		}
+		return out
+	case []any:
+		return copyMenu(v)
+	}
+	return value
+}
@@ -0,0 +1,184 @@
+package docs
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func menuLabels(t *testing.T, menu []any) map[string][]string {
+	t.Helper()
+
+	result := map[string][]string{}
+	for _, g := range menu {
+		group := g.(map[string]any)
+		label := group["label"].(string)
+		result[label] = []string{}
+		for _, item := range group["items"].([]any) {
+			result[label] = append(result[label], item.(map[string]any)["label"].(string))
+		}
+	}
+	return result
+}
+
+func TestBuildMenu(t *testing.T) {
+	menu, err := buildMenu(fstest.MapFS{
+		"README.md":  &fstest.MapFile{Data: []byte("# Home\n")},
+		"_index.yml": &fstest.MapFile{Data: []byte("title: Getting started\n")},
+		"about.md":   &fstest.MapFile{Data: []byte("---\ntitle: About\norder: -1\n---\ntext\n")},
+
+		"guide/_index.md":      &fstest.MapFile{Data: []byte("---\ntitle: Guide\norder: 1\n---\n")},
+		"guide/install.md":     &fstest.MapFile{Data: []byte("---\ntitle: Install\nweight: 2\nicon: terminal\n---\n")},
+		"guide/usage.md":       &fstest.MapFile{Data: []byte("---\ntitle: Usage\nweight: 1\n---\n")},
+		"guide/draft.md":       &fstest.MapFile{Data: []byte("---\ntitle: Draft\nhidden: true\n---\n")},
+		"guide/old/_index.yml": &fstest.MapFile{Data: []byte("hidden: true\n")},
+		"guide/old/legacy.md":  &fstest.MapFile{Data: []byte("# Legacy\n")},
+
+		"api/client.md":      &fstest.MapFile{Data: []byte("# Client\n")},
+		"private/_index.yml": &fstest.MapFile{Data: []byte("hidden: true\n")},
+		"private/notes.md":   &fstest.MapFile{Data: []byte("# Notes\n")},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string][]string{
+		"Getting started": {"About", "Home"},
+		"Guide":           {"Usage", "Install"},
+		"api":             {"Client"},
+	}, menuLabels(t, menu))
+
+	var order []string
+	for _, g := range menu {
+		order = append(order, g.(map[string]any)["label"].(string))
+	}
+	require.Equal(t, []string{"Getting started", "api", "Guide"}, order)
+
+	guide := menu[2].(map[string]any)["items"].([]any)
+	require.Equal(t, "/guide/install", guide[1].(map[string]any)["url"])
+	require.Equal(t, "terminal", guide[1].(map[string]any)["icon"])
+}
+
+func TestBuildMenu_InvalidPages(t *testing.T) {
+	menu, err := buildMenu(fstest.MapFS{
+		"README.md":        &fstest.MapFile{Data: []byte("# Home\n")},
+		"broken.md":        &fstest.MapFile{Data: []byte("---\ntitle: [\n---\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\norder: first\nweight: 1\n---\n")},
+		"guide/usage.md":   &fstest.MapFile{Data: []byte("---\ntitle: Usage\nweight: 2\n---\n")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"Documentation": {"Home"},
+		"guide":         {"Install", "Usage"},
+	}, menuLabels(t, menu))
+}
+
+func TestMergeMenu(t *testing.T) {
+	generated, err := buildMenu(fstest.MapFS{
+		"README.md":        &fstest.MapFile{Data: []byte("# Home\n")},
+		"_index.yml":       &fstest.MapFile{Data: []byte("title: Getting started\n")},
+		"guide/_index.md":  &fstest.MapFile{Data: []byte("---\ntitle: Guide\n---\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\nweight: 2\n---\n")},
+		"guide/usage.md":   &fstest.MapFile{Data: []byte("---\ntitle: Usage\nweight: 1\n---\n")},
+	})
+	require.NoError(t, err)
+
+	manual := []any{
+		map[string]any{
+			"label": "Guide",
+			"items": []any{
+				map[string]any{"label": "Installing", "url": "/guide/install"},
+				map[string]any{"label": "GitHub", "url": "https://github.com/titpetric/vuego"},
+			},
+		},
+		map[string]any{
+			"label": "Links",
+			"items": []any{
+				map[string]any{"label": "Blog", "url": "https://example.com"},
+			},
+		},
+	}
+
+	merged := mergeMenu(generated, manual)
+	require.Equal(t, map[string][]string{
+		"Getting started": {"Home"},
+		"Guide":           {"Usage", "Installing", "GitHub"},
+		"Links":           {"Blog"},
+	}, menuLabels(t, merged))
+}
+
+func TestModule_FillMenu(t *testing.T) {
+	content := fstest.MapFS{
+		"README.md":        &fstest.MapFile{Data: []byte("# Home\n")},
+		"_index.yml":       &fstest.MapFile{Data: []byte("title: Getting started\n")},
+		"guide/_index.md":  &fstest.MapFile{Data: []byte("---\ntitle: Guide\norder: 1\n---\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte("# Install\n")},
+		"api/client.md":    &fstest.MapFile{Data: []byte("# Client\n")},
+		"data/menu.yml":    &fstest.MapFile{Data: []byte("menu:\n  - label: Links\n    items:\n      - label: Blog\n        url: https://example.com\n")},
+	}
+
+	tests := []struct {
+		mode   MenuMode
+		groups []string
+	}{
+		{MenuMerge, []string{"Getting started", "api", "Guide", "Links"}},
+		{MenuAuto, []string{"Getting started", "api", "Guide"}},
+		{MenuManual, []string{"Links"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			m := &Module{FS: content, contentFS: content, menuMode: tc.mode}
+
+			var data map[string]any
+			m.fill(&data)
+
+			var groups []string
+			for _, g := range data["menu"].([]any) {
+				groups = append(groups, g.(map[string]any)["label"].(string))
+			}
+			require.Equal(t, tc.groups, groups)
+		})
+	}
+}
+
+func TestParseMenuMode(t *testing.T) {
+	mode, err := ParseMenuMode("auto")
+	require.NoError(t, err)
+	require.Equal(t, MenuAuto, mode)
+
+	_, err = ParseMenuMode("sideways")
+	require.Error(t, err)
+}
+
+func TestMenuCache(t *testing.T) {
+	content := fstest.MapFS{
+		"guide/install.md": &fstest.MapFile{Data: []byte("# Install\n")},
+	}
+
+	var cache menuCache
+	menu, ok := cache.get(content)
+	require.True(t, ok)
+	require.Equal(t, map[string][]string{"guide": {"Install"}}, menuLabels(t, menu))
+
+	// Callers may modify the menu they get.
+	menu[0].(map[string]any)["label"] = "changed"
+	menu, _ = cache.get(content)
+	require.Equal(t, "guide", menu[0].(map[string]any)["label"])
+
+	// Changes are picked up once the refresh interval passed.
+	content["guide/usage.md"] = &fstest.MapFile{Data: []byte("# Usage\n")}
+	menu, _ = cache.get(content)
+	require.Len(t, menuLabels(t, menu)["guide"], 1)
+
+	cache.checked = time.Time{}
+	menu, _ = cache.get(content)
+	require.Equal(t, []string{"Install", "Usage"}, menuLabels(t, menu)["guide"])
+
+	// A broken _index file keeps the previous menu.
+	content["guide/_index.yml"] = &fstest.MapFile{Data: []byte("title: [\n")}
+	cache.checked = time.Time{}
+	menu, ok = cache.get(content)
+	require.True(t, ok)
+	require.Equal(t, []string{"Install", "Usage"}, menuLabels(t, menu)["guide"])
+}
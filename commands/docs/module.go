@@ -3,15 +3,17 @@ package docs
 import (
 	"bytes"
 	"context"
-	"embed"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"log"
+	"maps"
 	"net/http"
 	"path"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	chi "github.com/go-chi/chi/v5"
 	"github.com/titpetric/platform"
@@ -19,19 +21,60 @@ import (
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/titpetric/vuego-cli/basecoat"
+	"github.com/titpetric/vuego-cli/server"
 )
 
-//go:embed templates
-var embeddedTemplates embed.FS
-
 // Module represents the docs module for the platform.
 type Module struct {
 	platform.UnimplementedModule
 
-	vuego vuego.Template
+	vuego  vuego.Template
+	search *searchIndex
+
+	FS fs.FS
+
+	contentFS fs.FS
+	menuMode  MenuMode
+	menuCache menuCache
+	strict    bool
+
+	schemaCache   schemaCache
+	redirectCache redirectCache
+
+	directives map[string]Directive
+
+	feedDir   string
+	feedCache feedCache
+	drafts    bool
+	baseURL   string
 
-	FS        fs.FS
-	indexTmpl string
+	contentDir   string
+	editURL      string
+	historyCache historyCache
+
+	// opts are reapplied to the module of every version.
+	opts           []ModuleOption
+	versions       []Version
+	defaultVersion string
+	locales        []Locale
+	defaultLocale  string
+
+	// version, locale and basePath are set on the modules serving a
+	// version or locale. localeRoot is the path the locale paths extend.
+	version    string
+	locale     string
+	localeRoot string
+	basePath   string
+}
+
+// ModuleOption configures a Module.
+type ModuleOption func(*Module)
+
+// WithMenuMode sets how the sidebar menu is built. The default is MenuMerge.
+func WithMenuMode(mode MenuMode) ModuleOption {
+	return func(m *Module) {
+		m.menuMode = mode
+	}
 }
 
 // handler wraps an error-returning handler function with platform error handling.
@@ -61,13 +104,46 @@ func notFound(err error) error {
 	return statusError{status: http.StatusNotFound, err: err}
 }
 
+// WithStrict makes failing @render, @file and @example directives fail the
+// page with an error. By default the failure is shown as an alert in place
+// of the directive output.
+func WithStrict(strict bool) ModuleOption {
+	return func(m *Module) {
+		m.strict = strict
+	}
+}
+
 // NewModule creates a new docs module with a filesystem.
-func NewModule(contentFS fs.FS) *Module {
+func NewModule(contentFS fs.FS, opts ...ModuleOption) *Module {
 	ofs := vuego.NewOverlayFS(contentFS, basecoat.FS)
-	return &Module{
-		FS:    ofs,
-		vuego: vuego.NewFS(ofs, vuego.WithLessProcessor()),
+	m := &Module{
+		FS:        ofs,
+		vuego:     vuego.NewFS(ofs, vuego.WithLessProcessor()),
+		contentFS: contentFS,
+		menuMode:  MenuMerge,
+
+		directives: make(map[string]Directive),
+		opts:       opts,
 	}
+	for _, d := range builtinDirectives() {
+		_ = m.RegisterDirective(d)
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// derive creates a module serving contentFS with the options, directives
+// and the version and locale of m.
+func (m *Module) derive(contentFS fs.FS) *Module {
+	d := NewModule(contentFS, m.opts...)
+	d.directives = maps.Clone(m.directives)
+	d.version = m.version
+	d.locale = m.locale
+	d.localeRoot = m.localeRoot
+	d.basePath = m.basePath
+	return d
 }
 
 // Name returns the module name.
@@ -76,15 +152,32 @@ func (m *Module) Name() string {
 }
 
 // Mount registers the docs routes.
-func (m *Module) Mount(_ context.Context, r platform.Router) error {
-	// Load embedded index template
-	indexData, err := embeddedTemplates.ReadFile("templates/index.vuego")
+func (m *Module) Mount(ctx context.Context, r platform.Router) error {
+	if len(m.versions) > 0 && m.version == "" {
+		return m.mountVersions(ctx, r)
+	}
+	if len(m.locales) > 0 && m.locale == "" {
+		return m.mountLocales(ctx, r)
+	}
+
+	var err error
+	m.search, err = newSearchIndex(m.contentFS)
 	if err != nil {
-		return fmt.Errorf("loading index template: %w", err)
+		return fmt.Errorf("building search index: %w", err)
 	}
-	m.indexTmpl = string(indexData)
 
 	r.Get("/", handler(m.serveIndex))
+	r.Get("/search", handler(m.serveSearch))
+	r.Get("/search.json", handler(m.serveSearchIndex))
+	r.Get(sitemapURL, handler(m.serveSitemap))
+	r.Get(strings.TrimSuffix(tagsURL, "/"), redirectTo(m.url(tagsURL)))
+	r.Get(tagsURL, handler(m.serveTags))
+	r.Get(tagsURL+"{tag}", handler(m.serveTag))
+	if m.feedDir != "" {
+		r.Get(atomURL, handler(m.serveAtom))
+		r.Get(rssURL, handler(m.serveRSS))
+	}
+	r.Post(exampleRenderURL, server.NewRenderHandler(m.FS, server.WithRenderLoadOption(vuego.WithLessProcessor())).ServeHTTP)
 	r.Get("/assets/*", http.FileServer(http.FS(m.FS)).ServeHTTP)
 	r.Get("/*", handler(m.serveDoc))
 
@@ -136,18 +229,53 @@ func (m *Module) serveDoc(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	// Try directory listing
-	if entries, err := fs.ReadDir(m.FS, urlPath); err == nil && len(entries) > 0 {
+	if entries, err := fs.ReadDir(m.contentFS, urlPath); err == nil && len(entries) > 0 {
 		return m.renderDirListing(ctx, w, urlPath)
 	}
 
+	// Try redirects of moved pages
+	rd, ok, err := m.redirect(urlPath)
+	if err != nil {
+		return err
+	}
+	if ok {
+		m.serveRedirect(w, r, rd)
+		return nil
+	}
+
 	return notFound(fmt.Errorf("not found: %s", urlPath))
 }
 
 // DocMeta represents frontmatter metadata for a doc.
 type DocMeta struct {
-	Title    string `yaml:"title"`
-	Subtitle string `yaml:"subtitle"`
-	Layout   string `yaml:"layout"`
+	Title       string    `yaml:"title"`
+	Subtitle    string    `yaml:"subtitle"`
+	Description string    `yaml:"description"`
+	Layout      string    `yaml:"layout"`
+	Date        time.Time `yaml:"date"`
+	Author      string    `yaml:"author"`
+	Tags        []string  `yaml:"tags"`
+	// Order sorts the page in the generated menu. Weight is used if the
+	// page has no order.
+	Order  int `yaml:"order"`
+	Weight int `yaml:"weight"`
+	// Icon is shown next to the page in the generated menu, Hidden
+	// leaves the page out of it.
+	Icon   string `yaml:"icon"`
+	Hidden bool   `yaml:"hidden"`
+	// Draft pages are left out of the sitemap and feeds, see WithDrafts.
+	Draft bool `yaml:"draft"`
+	// RedirectFrom lists old URLs of the page.
+	RedirectFrom []string `yaml:"redirect_from"`
+	// Aliases lists other URLs the page is known by.
+	Aliases []string `yaml:"aliases"`
+
+	// Page holds all frontmatter keys, including the ones above. Layouts
+	// receive it as the page data.
+	Page map[string]any `yaml:"-"`
+	// Warnings describe frontmatter values ignored for having the wrong
+	// type, such as a date that isn't a timestamp.
+	Warnings []string `yaml:"-"`
 }
 
 func (m *Module) renderDoc(ctx context.Context, w http.ResponseWriter, docPath string, content string) error {
@@ -157,19 +285,27 @@ func (m *Module) renderDoc(ctx context.Context, w http.ResponseWriter, docPath s
 		return fmt.Errorf("parsing doc: %w", err)
 	}
 
-	// Get directory for relative file lookups
-	docDir := path.Dir(docPath)
+	for _, warning := range meta.Warnings {
+		log.Printf("%s: %s", docPath, warning)
+	}
 
-	// Build HTML directly to preserve DOCTYPE, html, head, body tags
-	// Start with global data from data/*.yml files, then add doc-specific data
-	data := map[string]any{
-		"title":       meta.Title,
-		"subtitle":    meta.Subtitle,
-		"description": meta.Subtitle,
-		"content":     m.parseDirectives(ctx, body, docDir),
+	doc, err := m.parseDirectives(ctx, docPath, body, bodyLineOffset(content, body))
+	if err != nil {
+		return err
 	}
 
-	m.fill(&data)
+	if err := m.validateFrontmatter(meta.Page); err != nil {
+		if m.strict {
+			return fmt.Errorf("%s: %w", docPath, err)
+		}
+		doc.Content = m.renderErrorAlert(ctx, err.Error(), docPath+": frontmatter") + doc.Content
+	}
+
+	data := m.docData(docPath, meta, doc)
+
+	if err := m.renderPartials(ctx, doc.Partials, data); err != nil {
+		return err
+	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
@@ -188,7 +324,54 @@ func (m *Module) renderDoc(ctx context.Context, w http.ResponseWriter, docPath s
 		return fmt.Errorf("rendering layout: %w", err)
 	}
 
-	_, _ = w.Write(buf.Bytes())
+	html, err := m.injectAssets(docPath, content, buf.String())
+	if err != nil {
+		return err
+	}
+
+	_, _ = io.WriteString(w, html)
+	return nil
+}
+
+// docData returns the layout data of a rendered page: global data from
+// data/*.yml files, then the page specific data.
+func (m *Module) docData(docPath string, meta DocMeta, doc parsedDoc) map[string]any {
+	description := meta.Description
+	if description == "" {
+		description = meta.Subtitle
+	}
+
+	data := map[string]any{
+		"title":       meta.Title,
+		"subtitle":    meta.Subtitle,
+		"description": description,
+		"page":        meta.Page,
+		"tags":        m.tagLinks(meta.Tags),
+		"content":     doc.Content,
+		"toc":         doc.TOC,
+		"search":      m.url(searchURL),
+	}
+
+	if m.feedDir != "" {
+		data["feed"] = m.url(atomURL)
+	}
+
+	m.fill(&data)
+	m.fillLocales(data, docURL(docPath))
+	m.fillHistory(data, docPath)
+	return data
+}
+
+// renderPartials renders the partials required by directives and appends
+// them to the page content.
+func (m *Module) renderPartials(ctx context.Context, partials []string, data map[string]any) error {
+	for _, partial := range partials {
+		var buf bytes.Buffer
+		if err := m.vuego.Load(partial).Fill(data).Render(ctx, &buf); err != nil {
+			return fmt.Errorf("rendering %s: %w", partial, err)
+		}
+		data["content"] = data["content"].(string) + buf.String()
+	}
 	return nil
 }
 
@@ -201,6 +384,14 @@ func (m *Module) fill(dest *map[string]any) {
 	for _, filename := range files {
 		m.scan(dest, filename)
 	}
+
+	m.fillMenu(dest)
+	m.fillVersions(dest)
+
+	if *dest != nil {
+		menu, _ := (*dest)["menu"].([]any)
+		m.prefixMenu(menu)
+	}
 }
 
 func (m *Module) scan(dest *map[string]any, filename string) {
@@ -230,87 +421,26 @@ func (m *Module) renderVuego(ctx context.Context, w http.ResponseWriter, filePat
 		return fmt.Errorf("rendering vuego: %w", err)
 	}
 
-	_, _ = w.Write(buf.Bytes())
-	return nil
-}
-
-func (m *Module) renderDirListing(ctx context.Context, w http.ResponseWriter, dir string) error {
-	entries, err := fs.ReadDir(m.FS, dir)
+	source, _ := fs.ReadFile(m.FS, filePath)
+	html, err := m.injectAssets(filePath, string(source), buf.String())
 	if err != nil {
-		return fmt.Errorf("listing directory: %w", err)
-	}
-
-	type Entry struct {
-		Name  string
-		Path  string
-		IsDir bool
-	}
-
-	var items []Entry
-	for _, e := range entries {
-		name := e.Name()
-		if strings.HasPrefix(name, ".") {
-			continue
-		}
-		entryPath := path.Join(dir, name)
-		if dir == "." {
-			entryPath = name
-		}
-		items = append(items, Entry{
-			Name:  name,
-			Path:  "/" + entryPath,
-			IsDir: e.IsDir(),
-		})
+		return err
 	}
 
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].IsDir != items[j].IsDir {
-			return items[i].IsDir
-		}
-		return items[i].Name < items[j].Name
-	})
-
-	title := dir
-	if dir == "." {
-		title = "Documentation"
-	}
-
-	data := map[string]any{
-		"title":   title,
-		"entries": items,
-		"path":    dir,
-	}
-	m.fill(&data)
-
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	// Render the inline template content (without layout processing)
-	var contentBuf bytes.Buffer
-	if err := m.vuego.New().Fill(data).RenderString(ctx, &contentBuf, m.indexTmpl); err != nil {
-		return fmt.Errorf("rendering template: %w", err)
-	}
-
-	// Wrap in the base layout
-	data["content"] = contentBuf.String()
-	var buf bytes.Buffer
-	if err := m.vuego.Load("layouts/page.vuego").Fill(data).Render(ctx, &buf); err != nil {
-		return fmt.Errorf("rendering layout: %w", err)
-	}
-
-	_, _ = buf.WriteTo(w)
+	_, _ = io.WriteString(w, html)
 	return nil
 }
 
-func (m *Module) readFile(docDir, filePath string) string {
+func (m *Module) readFile(docDir, filePath string) (string, error) {
 	fullPath := path.Join(docDir, filePath)
 	content, err := fs.ReadFile(m.FS, fullPath)
 	if err != nil {
-		return fmt.Sprintf("<!-- error reading %s: %v -->", filePath, err)
+		return "", fmt.Errorf("reading %s: %w", filePath, err)
 	}
-	return string(content)
+	return string(content), nil
 }
 
-func (m *Module) renderVuegoFile(ctx context.Context, docDir, filePath string) string {
+func (m *Module) renderVuegoFile(ctx context.Context, docDir, filePath string) (string, error) {
 	fullPath := path.Join(docDir, filePath)
 
 	// Load sidecar data file, starting with global data
@@ -324,8 +454,32 @@ func (m *Module) renderVuegoFile(ctx context.Context, docDir, filePath string) s
 
 	var buf bytes.Buffer
 	if err := m.vuego.Load(fullPath).Fill(data).Render(ctx, &buf); err != nil {
-		return fmt.Sprintf("<!-- render error: %v -->", err)
+		return "", fmt.Errorf("rendering %s: %w", filePath, err)
+	}
+
+	source, _ := fs.ReadFile(m.FS, fullPath)
+	html, err := m.injectAssets(fullPath, string(source), buf.String())
+	if err != nil {
+		return "", fmt.Errorf("rendering %s: %w", filePath, err)
 	}
 
-	return buf.String()
+	return html, nil
+}
+
+// bodyLineOffset returns the number of lines preceding body in content.
+func bodyLineOffset(content, body string) int {
+	idx := strings.Index(content, body)
+	if idx < 0 || body == "" {
+		return 0
+	}
+	return strings.Count(content[:idx], "\n")
+}
+
+// injectAssets inlines the sidecar stylesheet and script of filePath into
+// its rendered HTML, unless the source already includes them.
+func (m *Module) injectAssets(filePath, source, html string) (string, error) {
+	assets := server.NewAssetInjector(m.FS, server.AssetInline)
+	assets.AddSidecars(filePath, filePath)
+	assets.SkipReferenced(source)
+	return assets.Inject(html)
 }
@@ -0,0 +1,38 @@
+package docs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/vuego-cli/commands/docs"
+	"github.com/titpetric/vuego-cli/server"
+)
+
+func TestModule_Render(t *testing.T) {
+	m := docs.NewModule(fstest.MapFS{
+		"components/badge.vuego": &fstest.MapFile{Data: []byte(`<span class="badge">{{ label }}</span>`)},
+	})
+
+	r := chi.NewRouter()
+	require.NoError(t, m.Mount(context.Background(), r))
+
+	body := `{"name":"page.vuego","template":"<template include=\"components/badge.vuego\" :label=\"label\"></template>","data":"label: New"}`
+	req := httptest.NewRequest(http.MethodPost, "/render", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp server.RenderResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Empty(t, resp.Error)
+	require.Contains(t, resp.HTML, `<span class="badge">New</span>`)
+}
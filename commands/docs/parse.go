@@ -2,17 +2,97 @@ package docs
 
 import (
 	"context"
-	"html"
-	"path/filepath"
+	"fmt"
+	"reflect"
 	"strings"
 
-	blackfriday "github.com/russross/blackfriday/v2"
 	yaml "gopkg.in/yaml.v3"
 )
 
+// parsedDoc is the rendered body of a markdown page.
+type parsedDoc struct {
+	// Content is the rendered HTML.
+	Content string
+	// TOC is the table of contents of the page headings.
+	TOC []any
+	// Partials are templates directives require on the page, such as
+	// scripts, in the order they were first required.
+	Partials []string
+}
+
 // parseDirectives parses @ directives in the markdown body.
 // It processes directives on raw markdown, then renders markdown on non-directive content.
-func (m *Module) parseDirectives(ctx context.Context, body, docDir string) string {
+//
+// The docPath and lineOffset locate the body within its file for error
+// reporting. Directive failures render as an alert, or fail the page in
+// strict mode.
+func (m *Module) parseDirectives(ctx context.Context, docPath, body string, lineOffset int) (parsedDoc, error) {
+	p := &pageParser{
+		m:    m,
+		file: docPath,
+		page: &pageState{
+			file:     docPath,
+			headings: newHeadingIDs(),
+		},
+	}
+
+	content, err := p.parse(ctx, body, lineOffset)
+	if err != nil {
+		return parsedDoc{}, err
+	}
+	return parsedDoc{
+		Content:  content,
+		TOC:      p.page.headings.toc(),
+		Partials: p.page.partials,
+	}, nil
+}
+
+// pageState is shared by the parsers of a page and the files it includes.
+// It is owned by a single render, so no locking is needed.
+type pageState struct {
+	file      string
+	headings  *headingIDs
+	partials  []string
+	tabGroups int
+}
+
+// pageParser renders the markdown of a page, expanding registered
+// directives.
+type pageParser struct {
+	m    *Module
+	file string
+	page *pageState
+
+	// includes are the files including file, outermost first.
+	includes []string
+}
+
+// require adds a partial to the page, once.
+func (p *pageParser) require(partial string) {
+	for _, existing := range p.page.partials {
+		if existing == partial {
+			return
+		}
+	}
+	p.page.partials = append(p.page.partials, partial)
+}
+
+// renderTabGroup renders a tab group with the next tab group ID of the
+// page. Named groups require the script syncing them.
+func (p *pageParser) renderTabGroup(tg *TabGroup) string {
+	if len(tg.Tabs) == 0 {
+		return ""
+	}
+
+	p.page.tabGroups++
+	tg.ID = tabGroupID(p.page.file, p.page.tabGroups)
+	if tg.Name != "" {
+		p.require("partials/tab-groups.vuego")
+	}
+	return p.m.renderTabGroup(tg)
+}
+
+func (p *pageParser) parse(ctx context.Context, body string, lineOffset int) (string, error) {
 	lines := strings.Split(body, "\n")
 	var result []string
 	var currentTabs *TabGroup
@@ -21,27 +101,37 @@ func (m *Module) parseDirectives(ctx context.Context, body, docDir string) strin
 
 	flushMarkdown := func() {
 		if len(markdownBuffer) > 0 {
-			result = append(result, renderMarkdown(strings.Join(markdownBuffer, "\n")))
+			result = append(result, p.renderMarkdown(strings.Join(markdownBuffer, "\n")))
 			markdownBuffer = nil
 		}
 	}
 
+	// addTab adds a tab to the open tab group, or renders it on its own.
+	addTab := func(tab Tab) {
+		if inTabsBlock && currentTabs != nil {
+			currentTabs.Tabs = append(currentTabs.Tabs, tab)
+		} else {
+			result = append(result, p.m.renderSingleTab(tab))
+		}
+	}
+
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
 		trimmed := strings.TrimSpace(line)
+		lineNo := lineOffset + i + 1
 
-		// Handle @tabs directive - starts a tab group
-		if trimmed == "@tabs" {
+		// Handle @tabs directive - starts a tab group, optionally named
+		if trimmed == "@tabs" || strings.HasPrefix(trimmed, "@tabs ") {
 			flushMarkdown()
 			inTabsBlock = true
-			currentTabs = &TabGroup{}
+			currentTabs = &TabGroup{Name: strings.TrimSpace(strings.TrimPrefix(trimmed, "@tabs"))}
 			continue
 		}
 
 		// If in tabs block and we hit a blank line, end the tabs block
 		if inTabsBlock && trimmed == "" {
 			if currentTabs != nil && len(currentTabs.Tabs) > 0 {
-				result = append(result, m.renderTabGroup(currentTabs))
+				result = append(result, p.renderTabGroup(currentTabs))
 			}
 			inTabsBlock = false
 			currentTabs = nil
@@ -49,42 +139,76 @@ func (m *Module) parseDirectives(ctx context.Context, body, docDir string) strin
 			continue
 		}
 
-		// Handle @render directive
-		if strings.HasPrefix(trimmed, "@render ") {
+		// Handle registered directives
+		if directive, args, ok := p.m.lookupDirective(trimmed); ok {
 			flushMarkdown()
-			tab := m.parseRenderDirective(ctx, trimmed, docDir)
-			if inTabsBlock && currentTabs != nil {
-				currentTabs.Tabs = append(currentTabs.Tabs, tab)
-			} else {
-				result = append(result, m.renderSingleTab(tab))
+
+			dc := &DirectiveContext{
+				File:   p.file,
+				Line:   lineNo,
+				Source: trimmed,
+				module: p.m,
+				page:   p,
 			}
-			continue
-		}
 
-		// Handle @file directive
-		if strings.HasPrefix(trimmed, "@file ") {
-			flushMarkdown()
-			tab := m.parseFileDirective(trimmed, docDir)
-			if inTabsBlock && currentTabs != nil {
-				currentTabs.Tabs = append(currentTabs.Tabs, tab)
-			} else {
-				result = append(result, m.renderSingleTab(tab))
+			var err error
+			if directive.Block {
+				end := p.m.blockEnd(lines, i+1)
+				if end < 0 {
+					err = fmt.Errorf("missing @end for @%s", directive.Name)
+					end = len(lines)
+				}
+				dc.Body = strings.Join(lines[i+1:min(end, len(lines))], "\n")
+				i = end
 			}
-			continue
-		}
 
-		// Handle @example directive
-		if strings.HasPrefix(trimmed, "@example ") {
-			flushMarkdown()
-			tabs := m.parseExampleDirective(ctx, trimmed, docDir)
-			result = append(result, m.renderTabGroup(tabs))
+			var out DirectiveOutput
+			if err == nil {
+				dc.Args, err = directive.ParseArgs(args)
+			}
+			if err == nil {
+				out, err = directive.Render(ctx, dc)
+			}
+			if err != nil {
+				alert, err := p.failed(ctx, dc, err)
+				if err != nil {
+					return "", err
+				}
+				if inTabsBlock && currentTabs != nil {
+					currentTabs.Tabs = append(currentTabs.Tabs, Tab{Label: "Error", Content: alert})
+				} else {
+					result = append(result, alert)
+				}
+				continue
+			}
+
+			switch {
+			case out.Tab != nil:
+				addTab(*out.Tab)
+			case len(out.Tabs) > 0:
+				result = append(result, p.renderTabGroup(&TabGroup{Tabs: out.Tabs}))
+			case out.File != "":
+				rendered, err := p.include(ctx, dc, out)
+				if err != nil {
+					return "", err
+				}
+				result = append(result, rendered)
+			case out.Markdown != "":
+				rendered, err := p.parse(ctx, out.Markdown, dc.Line)
+				if err != nil {
+					return "", err
+				}
+				result = append(result, rendered)
+			default:
+				result = append(result, out.HTML)
+			}
 			continue
 		}
 
 		// Regular line
 		if inTabsBlock && currentTabs != nil && len(currentTabs.Tabs) > 0 {
 			// Flush tabs before continuing with normal content
-			result = append(result, m.renderTabGroup(currentTabs))
+			result = append(result, p.renderTabGroup(currentTabs))
 			inTabsBlock = false
 			currentTabs = nil
 		}
@@ -96,63 +220,76 @@ func (m *Module) parseDirectives(ctx context.Context, body, docDir string) strin
 
 	// Flush any remaining tabs
 	if currentTabs != nil && len(currentTabs.Tabs) > 0 {
-		result = append(result, m.renderTabGroup(currentTabs))
+		result = append(result, p.renderTabGroup(currentTabs))
 	}
 
-	return strings.Join(result, "\n")
+	return strings.Join(result, "\n"), nil
 }
 
-func (m *Module) parseRenderDirective(ctx context.Context, line, docDir string) Tab {
-	// @render "Label" file.vuego
-	parts := parseDirectiveParts(strings.TrimPrefix(line, "@render "))
-	if len(parts) < 2 {
-		return Tab{Label: "Preview", Content: "<!-- missing args -->"}
+// include renders the markdown of another file in place of a directive.
+// Directives in the included file resolve relative to it. A file that
+// includes itself, directly or through other files, is reported as an
+// include cycle.
+func (p *pageParser) include(ctx context.Context, dc *DirectiveContext, out DirectiveOutput) (string, error) {
+	chain := append(append([]string{}, p.includes...), p.file)
+	for i, file := range chain {
+		if file == out.File {
+			cycle := strings.Join(append(chain[i:], out.File), " -> ")
+			return p.failed(ctx, dc, fmt.Errorf("include cycle: %s", cycle))
+		}
 	}
-	label := parts[0]
-	filePath := parts[1]
 
-	rendered := m.renderVuegoFile(ctx, docDir, filePath)
-	return Tab{Label: label, Content: rendered, IsCode: false}
-}
-
-func (m *Module) parseFileDirective(line, docDir string) Tab {
-	// @file "Label" file.vuego
-	parts := parseDirectiveParts(strings.TrimPrefix(line, "@file "))
-	if len(parts) < 2 {
-		return Tab{Label: "Code", Content: "<!-- missing args -->"}
+	child := &pageParser{
+		m:        p.m,
+		file:     out.File,
+		page:     p.page,
+		includes: chain,
 	}
-	label := parts[0]
-	filePath := parts[1]
 
-	content := m.readFile(docDir, filePath)
-	ext := filepath.Ext(filePath)
-	mode := strings.TrimPrefix(ext, ".")
-	if mode == "vuego" {
-		mode = "html"
-	} else if mode == "yml" {
-		mode = "yaml"
-	}
+	_, body := splitFrontmatter(out.Markdown)
+	return child.parse(ctx, body, bodyLineOffset(out.Markdown, body))
+}
 
-	return Tab{Label: label, Content: content, IsCode: true, Mode: mode}
+// failed reports a directive error as an alert to render in place of the
+// directive output. In strict mode the error is returned instead.
+func (p *pageParser) failed(ctx context.Context, dc *DirectiveContext, err error) (string, error) {
+	directiveErr := &DirectiveError{File: dc.File, Line: dc.Line, Directive: dc.Source, Err: err}
+	if p.m.strict {
+		return "", directiveErr
+	}
+	return p.m.renderDirectiveError(ctx, directiveErr), nil
 }
 
-func (m *Module) parseExampleDirective(ctx context.Context, line, docDir string) *TabGroup {
-	// @example file.vuego file.yaml
-	parts := parseDirectiveParts(strings.TrimPrefix(line, "@example "))
-	if len(parts) < 1 {
-		return &TabGroup{}
+// lookupDirective returns the registered directive a line invokes and its
+// unparsed arguments.
+func (m *Module) lookupDirective(line string) (Directive, string, bool) {
+	if !strings.HasPrefix(line, "@") {
+		return Directive{}, "", false
 	}
 
-	vuegoPart := parts[0]
-	rendered := m.renderVuegoFile(ctx, docDir, vuegoPart)
-	code := m.readFile(docDir, vuegoPart)
+	name, args, _ := strings.Cut(line[1:], " ")
+	directive, ok := m.directives[name]
+	return directive, strings.TrimSpace(args), ok
+}
 
-	return &TabGroup{
-		Tabs: []Tab{
-			{Label: "Preview", Content: rendered, IsCode: false},
-			{Label: "Code", Content: code, IsCode: true, Mode: "html"},
-		},
+// blockEnd returns the index of the @end line closing a block that starts
+// at lines[start], or -1. Nested block directives need their own @end.
+func (m *Module) blockEnd(lines []string, start int) int {
+	depth := 0
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "@end" || strings.HasPrefix(trimmed, "@end ") {
+			if depth == 0 {
+				return i
+			}
+			depth--
+			continue
+		}
+		if directive, _, ok := m.lookupDirective(trimmed); ok && directive.Block {
+			depth++
+		}
 	}
+	return -1
 }
 
 func parseDirectiveParts(s string) []string {
@@ -183,60 +320,88 @@ func parseDirectiveParts(s string) []string {
 	return parts
 }
 
+// parseFrontmatter parses the frontmatter of a document and returns it
+// with the document body. Fields of DocMeta are decoded leniently: a single
+// value is accepted for a list, and a value of the wrong type is dropped
+// with a warning in DocMeta.Warnings. Only invalid YAML is an error.
 func parseFrontmatter(content string) (DocMeta, string, error) {
 	var meta DocMeta
-	if !strings.HasPrefix(content, "---") {
-		return meta, content, nil
-	}
+	frontmatter, body := splitFrontmatter(content)
 
-	parts := strings.SplitN(content, "---", 3)
-	if len(parts) < 3 {
-		return meta, content, nil
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		return meta, body, err
+	}
+	if len(doc.Content) == 0 {
+		meta.Page = map[string]any{}
+		return meta, body, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return meta, body, fmt.Errorf("frontmatter is not a mapping")
 	}
 
-	err := yaml.Unmarshal([]byte(parts[1]), &meta)
-	return meta, strings.TrimSpace(parts[2]), err
-}
-
-type customRenderer struct {
-	*blackfriday.HTMLRenderer
+	if err := root.Decode(&meta.Page); err != nil {
+		return meta, body, err
+	}
+	meta.Warnings = lenientFields(root, reflect.TypeOf(meta))
+	if err := root.Decode(&meta); err != nil {
+		return meta, body, err
+	}
+	return meta, body, nil
 }
 
-func (r *customRenderer) RenderNode(w *strings.Builder, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
-	if node.Type == blackfriday.CodeBlock {
-		lang := string(node.CodeBlockData.Info)
-		if lang == "" {
-			lang = "text"
+// lenientFields adjusts the values of the struct fields of t in mapping,
+// so decoding into t can't fail. Single values of list fields are wrapped
+// in a list, values that still don't decode are removed. It returns a
+// warning for every removed value.
+func lenientFields(mapping *yaml.Node, t reflect.Type) []string {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			fields[name] = t.Field(i).Type
 		}
-		w.WriteString(`<pre class="grid text-sm max-h-[650px] overflow-y-auto rounded-xl scrollbar"><code class="language-`)
-		w.WriteString(lang)
-		w.WriteString(` !bg-muted/40 !p-3.5">`)
-		w.WriteString(html.EscapeString(string(node.Literal)))
-		w.WriteString("</code></pre>\n")
-		return blackfriday.GoToNext
-	}
-	if node.Type == blackfriday.Code {
-		code := string(node.Literal)
-		if strings.HasPrefix(code, "<") {
-			w.WriteString(`<code class="highlight language-html">`)
-		} else {
-			w.WriteString(`<code class="highlight">`)
+	}
+
+	var warnings []string
+	content := mapping.Content[:0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if fieldType, ok := fields[key.Value]; ok {
+			if fieldType.Kind() == reflect.Slice && value.Kind == yaml.ScalarNode && value.Tag != "!!null" {
+				value = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{value}}
+			}
+			if err := value.Decode(reflect.New(fieldType).Interface()); err != nil {
+				warnings = append(warnings, fmt.Sprintf("ignoring invalid %s %q", key.Value, nodeValue(value)))
+				continue
+			}
 		}
-		w.WriteString(html.EscapeString(code))
-		w.WriteString("</code>")
-		return blackfriday.GoToNext
+		content = append(content, key, value)
 	}
-	return r.HTMLRenderer.RenderNode(w, node, entering)
+	mapping.Content = content
+	return warnings
 }
 
-func renderMarkdown(in string) string {
-	renderer := &customRenderer{
-		HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{}),
+// nodeValue returns the source text of a scalar node, or its kind.
+func nodeValue(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
 	}
-	var buf strings.Builder
-	node := blackfriday.New().Parse([]byte(in))
-	node.Walk(func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
-		return renderer.RenderNode(&buf, n, entering)
-	})
-	return buf.String()
+	return strings.TrimPrefix(node.Tag, "!!")
+}
+
+// splitFrontmatter separates the YAML frontmatter from the document body.
+// The frontmatter is empty if the document has none.
+func splitFrontmatter(content string) (string, string) {
+	if !strings.HasPrefix(content, "---") {
+		return "", content
+	}
+
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return "", content
+	}
+
+	return parts[1], strings.TrimSpace(parts[2])
 }
@@ -0,0 +1,240 @@
+package docs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redirectsFile lists redirects in the content root, one per line as
+// "from to [status]". Blank lines and lines starting with # are ignored.
+const redirectsFile = "_redirects"
+
+// Redirect sends requests for a moved page to its new URL.
+type Redirect struct {
+	From   string
+	To     string
+	Status int
+	// Source is the file declaring the redirect, and Line its line in
+	// the _redirects file.
+	Source string
+	Line   int
+}
+
+// cleanRedirectPath normalizes the path a redirect is matched against.
+func cleanRedirectPath(p string) string {
+	p = strings.TrimSuffix(p, ".md")
+	return path.Clean("/" + p)
+}
+
+// parseRedirects parses the content of a _redirects file.
+func parseRedirects(content string) ([]Redirect, error) {
+	var redirects []Redirect
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected \"from to [status]\"", line)
+		}
+
+		rd := Redirect{
+			From:   cleanRedirectPath(fields[0]),
+			To:     fields[1],
+			Status: http.StatusMovedPermanently,
+			Source: redirectsFile,
+			Line:   line,
+		}
+		if len(fields) == 3 {
+			status, err := strconv.Atoi(fields[2])
+			if err != nil || status < 300 || status > 308 {
+				return nil, fmt.Errorf("line %d: invalid redirect status %q", line, fields[2])
+			}
+			rd.Status = status
+		}
+		redirects = append(redirects, rd)
+	}
+	return redirects, scanner.Err()
+}
+
+// Redirects returns the redirects of contentFS: the ones in the _redirects
+// file, then the redirect_from and aliases of every page. The first
+// redirect from a path wins. Pages with invalid frontmatter are skipped.
+func Redirects(contentFS fs.FS) ([]Redirect, error) {
+	var redirects []Redirect
+	content, err := fs.ReadFile(contentFS, redirectsFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		redirects, err = parseRedirects(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", redirectsFile, err)
+		}
+	}
+
+	err = walkMarkdown(contentFS, func(filePath string) error {
+		content, err := fs.ReadFile(contentFS, filePath)
+		if err != nil {
+			return err
+		}
+		meta, _, err := parseFrontmatter(string(content))
+		if err != nil {
+			log.Printf("skipping %s: %v", filePath, err)
+			return nil
+		}
+		for _, from := range append(meta.RedirectFrom, meta.Aliases...) {
+			redirects = append(redirects, Redirect{
+				From:   cleanRedirectPath(from),
+				To:     docURL(filePath),
+				Status: http.StatusMovedPermanently,
+				Source: filePath,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	unique := redirects[:0]
+	for _, rd := range redirects {
+		if seen[rd.From] {
+			continue
+		}
+		seen[rd.From] = true
+		unique = append(unique, rd)
+	}
+	return unique, nil
+}
+
+// redirectCache holds the redirects by path until the markdown files or
+// the _redirects file change, checked at most every searchRefreshInterval.
+type redirectCache struct {
+	mu        sync.Mutex
+	redirects map[string]Redirect
+	err       error
+	built     bool
+	signature string
+	checked   time.Time
+}
+
+// get returns the redirects of contentFS by path, reading them again if
+// the content changed. An error reading them is kept until then too.
+func (c *redirectCache) get(contentFS fs.FS) (map[string]Redirect, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.built && time.Since(c.checked) < searchRefreshInterval {
+		return c.redirects, c.err
+	}
+	c.checked = time.Now()
+
+	signature, err := redirectSignature(contentFS)
+	if err != nil {
+		return nil, err
+	}
+	if c.built && signature == c.signature {
+		return c.redirects, c.err
+	}
+
+	redirects, err := Redirects(contentFS)
+	byFrom := make(map[string]Redirect, len(redirects))
+	for _, rd := range redirects {
+		byFrom[rd.From] = rd
+	}
+	c.redirects, c.err, c.built, c.signature = byFrom, err, true, signature
+	return c.redirects, c.err
+}
+
+// redirectSignature summarizes the files redirects are read from, like
+// markdownSignature, including the _redirects file.
+func redirectSignature(contentFS fs.FS) (string, error) {
+	signature, err := markdownSignature(contentFS)
+	if err != nil {
+		return "", err
+	}
+	info, err := fs.Stat(contentFS, redirectsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return signature, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s:%d:%d;", signature, redirectsFile, info.Size(), info.ModTime().UnixNano()), nil
+}
+
+// redirect returns the redirect for a request path. Pages and files
+// that exist are served before redirects are considered.
+func (m *Module) redirect(urlPath string) (Redirect, bool, error) {
+	redirects, err := m.redirectCache.get(m.contentFS)
+	if err != nil {
+		return Redirect{}, false, err
+	}
+	rd, ok := redirects[cleanRedirectPath(urlPath)]
+	return rd, ok, nil
+}
+
+// serveRedirect redirects the request to the target of rd, keeping the
+// query string unless the target sets one.
+func (m *Module) serveRedirect(w http.ResponseWriter, r *http.Request, rd Redirect) {
+	target := rd.To
+	if strings.HasPrefix(target, "/") {
+		target = m.url(target)
+	}
+	if r.URL.RawQuery != "" && !strings.Contains(target, "?") {
+		target += "?" + r.URL.RawQuery
+	}
+	http.Redirect(w, r, target, rd.Status)
+}
+
+// redirectStub is the page written for a redirect in static builds, where
+// the server cannot respond with a redirect status.
+func redirectStub(target string) []byte {
+	target = html.EscapeString(target)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<!DOCTYPE html>\n<html>\n<head>\n")
+	fmt.Fprintf(&buf, "<meta charset=\"utf-8\">\n<title>Redirecting…</title>\n")
+	fmt.Fprintf(&buf, "<link rel=\"canonical\" href=\"%s\">\n", target)
+	fmt.Fprintf(&buf, "<meta name=\"robots\" content=\"noindex\">\n")
+	fmt.Fprintf(&buf, "<meta http-equiv=\"refresh\" content=\"0; url=%s\">\n", target)
+	fmt.Fprintf(&buf, "</head>\n<body>\n<p>This page has moved to <a href=\"%s\">%s</a>.</p>\n</body>\n</html>\n", target, target)
+	return buf.Bytes()
+}
+
+// WriteRedirectStubs writes an index.html redirect stub for every redirect
+// of contentPath below outDir, for hosting the docs as static files.
+func WriteRedirectStubs(contentPath, outDir string) error {
+	redirects, err := Redirects(os.DirFS(contentPath))
+	if err != nil {
+		return err
+	}
+	for _, rd := range redirects {
+		if rd.From == "/" {
+			continue
+		}
+		dir := filepath.Join(outDir, filepath.FromSlash(strings.TrimPrefix(rd.From, "/")))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "index.html"), redirectStub(rd.To), 0o644); err != nil {
+			return fmt.Errorf("writing redirect stub: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package docs
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRedirects(t *testing.T) {
+	redirects, err := parseRedirects("# comment\n/a /b\n  /c/   /d   307\n")
+	require.NoError(t, err)
+	require.Equal(t, []Redirect{
+		{From: "/a", To: "/b", Status: http.StatusMovedPermanently, Source: redirectsFile, Line: 2},
+		{From: "/c", To: "/d", Status: http.StatusTemporaryRedirect, Source: redirectsFile, Line: 3},
+	}, redirects)
+
+	_, err = parseRedirects("/a\n")
+	require.EqualError(t, err, `line 1: expected "from to [status]"`)
+
+	_, err = parseRedirects("/a /b 200\n")
+	require.EqualError(t, err, `line 1: invalid redirect status "200"`)
+}
+
+func TestRedirects(t *testing.T) {
+	redirects, err := Redirects(fstest.MapFS{
+		redirectsFile:      &fstest.MapFile{Data: []byte("# Moved pages\n/old-install /guide/install\n/setup/ /guide/install 302\n\n/chat https://chat.example.com\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\nredirect_from: [/setup, /install.md]\naliases: [/get-started]\n---\n")},
+		"guide/README.md":  &fstest.MapFile{Data: []byte("# Guide\n")},
+	})
+	require.NoError(t, err)
+
+	byFrom := make(map[string]Redirect)
+	for _, rd := range redirects {
+		byFrom[rd.From] = rd
+	}
+	require.Len(t, byFrom, 5)
+	require.Equal(t, http.StatusFound, byFrom["/setup"].Status, "the _redirects file wins")
+	require.Equal(t, "/guide/install", byFrom["/install"].To)
+	require.Equal(t, "guide/install.md", byFrom["/get-started"].Source)
+}
+
+func TestRedirects_Serve(t *testing.T) {
+	content := fstest.MapFS{
+		redirectsFile:      &fstest.MapFile{Data: []byte("# Moved pages\n/old-install /guide/install\n/setup/ /guide/install 302\n\n/chat https://chat.example.com\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\nredirect_from: [/setup, /install.md]\naliases: [/get-started]\n---\n")},
+		"guide/README.md":  &fstest.MapFile{Data: []byte("# Guide\n")},
+	}
+	m := NewModule(content)
+
+	rec := serveFeed(t, m, "/old-install?tab=linux")
+	require.Equal(t, http.StatusMovedPermanently, rec.Code)
+	require.Equal(t, "/guide/install?tab=linux", rec.Header().Get("Location"))
+
+	rec = serveFeed(t, m, "/get-started/")
+	require.Equal(t, http.StatusMovedPermanently, rec.Code)
+	require.Equal(t, "/guide/install", rec.Header().Get("Location"))
+
+	rec = serveFeed(t, m, "/chat")
+	require.Equal(t, "https://chat.example.com", rec.Header().Get("Location"))
+
+	m = NewModule(content)
+	m.basePath = "/v/2"
+	rec = serveFeed(t, m, "/setup")
+	require.Equal(t, http.StatusFound, rec.Code)
+	require.Equal(t, "/v/2/guide/install", rec.Header().Get("Location"))
+}
+
+func TestRedirects_Cache(t *testing.T) {
+	content := fstest.MapFS{
+		redirectsFile: &fstest.MapFile{Data: []byte("/a /b\n"), ModTime: time.Unix(1, 0)},
+	}
+	m := NewModule(content)
+
+	rd, ok, err := m.redirect("/a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "/b", rd.To)
+
+	content[redirectsFile] = &fstest.MapFile{Data: []byte("/a /c\n"), ModTime: time.Unix(2, 0)}
+	rd, _, _ = m.redirect("/a")
+	require.Equal(t, "/b", rd.To, "cached until the next check")
+
+	m.redirectCache.checked = time.Time{}
+	rd, _, _ = m.redirect("/a")
+	require.Equal(t, "/c", rd.To)
+
+	content[redirectsFile] = &fstest.MapFile{Data: []byte("/a\n"), ModTime: time.Unix(3, 0)}
+	m.redirectCache.checked = time.Time{}
+	_, _, err = m.redirect("/a")
+	require.Error(t, err)
+}
+
+func TestWriteRedirectStubs(t *testing.T) {
+	files := map[string]string{
+		redirectsFile:      "/old-install /guide/install\n",
+		"guide/install.md": "---\ntitle: Install\naliases: [/get-started]\n---\n",
+	}
+	content := t.TempDir()
+	for name, data := range files {
+		p := filepath.Join(content, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(data), 0o644))
+	}
+
+	out := t.TempDir()
+	require.NoError(t, WriteRedirectStubs(content, out))
+
+	stub, err := os.ReadFile(filepath.Join(out, "old-install", "index.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(stub), `<meta http-equiv="refresh" content="0; url=/guide/install">`)
+	require.Contains(t, string(stub), `<link rel="canonical" href="/guide/install">`)
+
+	require.FileExists(t, filepath.Join(out, "get-started", "index.html"))
+}
+
+func TestCheck_Redirects(t *testing.T) {
+	content := fstest.MapFS{
+		redirectsFile:      &fstest.MapFile{Data: []byte("/a /guide/install\n/b /guide/missing\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte("# Install\n")},
+	}
+
+	problems, err := Check(content)
+	require.NoError(t, err)
+	require.Equal(t, []Problem{
+		{File: redirectsFile, Line: 2, Message: "broken redirect: /guide/missing"},
+	}, problems)
+
+	content[redirectsFile] = &fstest.MapFile{Data: []byte("/a\n")}
+	problems, err = Check(content)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0].Message, "expected")
+}
@@ -0,0 +1,417 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// SearchDocument is a markdown page in the search index.
+type SearchDocument struct {
+	URL      string         `json:"url"`
+	Title    string         `json:"title"`
+	Headings []string       `json:"headings,omitempty"`
+	Text     string         `json:"text"`
+	Meta     map[string]any `json:"meta,omitempty"`
+}
+
+// SearchResult is a ranked search hit with a snippet of matching text.
+type SearchResult struct {
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet,omitempty"`
+	Score   int    `json:"score"`
+}
+
+// searchIndex holds the indexed markdown pages of a content filesystem.
+// It rebuilds itself when the markdown files change.
+type searchIndex struct {
+	fs fs.FS
+
+	mu        sync.RWMutex
+	docs      []SearchDocument
+	signature string
+	checked   time.Time
+}
+
+// searchRefreshInterval bounds how often the content is checked for changes.
+const searchRefreshInterval = 2 * time.Second
+
+// searchURL is the endpoint the search partial queries.
+const searchURL = "/search"
+
+// searchLimit is the maximum number of results returned for a query.
+const searchLimit = 20
+
+func newSearchIndex(contentFS fs.FS) (*searchIndex, error) {
+	idx := &searchIndex{fs: contentFS}
+	if err := idx.rebuild(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Documents returns the indexed documents, refreshing the index first
+// when the content has changed.
+func (idx *searchIndex) Documents() []SearchDocument {
+	idx.refresh()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.docs
+}
+
+// Search returns the documents matching all query terms, best match first.
+func (idx *searchIndex) Search(query string) []SearchResult {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []SearchResult
+	for _, doc := range idx.Documents() {
+		score := scoreDocument(doc, terms)
+		if score == 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			URL:     doc.URL,
+			Title:   doc.Title,
+			Snippet: snippet(doc.Text, terms),
+			Score:   score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].URL < results[j].URL
+	})
+
+	if len(results) > searchLimit {
+		results = results[:searchLimit]
+	}
+	return results
+}
+
+// refresh rebuilds the index if markdown files were added, removed or
+// modified since the last build. A failed rebuild is logged and the
+// previous index is kept.
+func (idx *searchIndex) refresh() {
+	idx.mu.RLock()
+	fresh := time.Since(idx.checked) < searchRefreshInterval
+	idx.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	signature, err := markdownSignature(idx.fs)
+
+	idx.mu.Lock()
+	idx.checked = time.Now()
+	changed := err == nil && signature != idx.signature
+	idx.mu.Unlock()
+
+	if err != nil {
+		log.Printf("search: checking for changes: %v", err)
+	}
+	if changed {
+		if err := idx.rebuild(); err != nil {
+			log.Printf("search: rebuilding index: %v", err)
+		}
+	}
+}
+
+// rebuild indexes the markdown files. Pages with invalid frontmatter are
+// logged and left out of the index.
+func (idx *searchIndex) rebuild() error {
+	signature, err := markdownSignature(idx.fs)
+	if err != nil {
+		return err
+	}
+
+	var docs []SearchDocument
+	err = walkMarkdown(idx.fs, func(filePath string) error {
+		content, err := fs.ReadFile(idx.fs, filePath)
+		if err != nil {
+			return err
+		}
+		doc, err := indexDocument(filePath, string(content))
+		if err != nil {
+			log.Printf("search: skipping %s: %v", filePath, err)
+			return nil
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	idx.docs = docs
+	idx.signature = signature
+	idx.checked = time.Now()
+	idx.mu.Unlock()
+	return nil
+}
+
+// WriteJSON writes the full index as JSON, for use in offline builds.
+func (idx *searchIndex) WriteJSON(w io.Writer) error {
+	return writeDocumentsJSON(w, idx.Documents())
+}
+
+func writeDocumentsJSON(w io.Writer, docs []SearchDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(docs)
+}
+
+// ExportSearchIndex writes the search index of the markdown files in
+// contentFS as JSON, so static builds can search without a server.
+func ExportSearchIndex(contentFS fs.FS, w io.Writer) error {
+	idx, err := newSearchIndex(contentFS)
+	if err != nil {
+		return err
+	}
+	return idx.WriteJSON(w)
+}
+
+// walkMarkdown calls fn for every markdown file, skipping hidden entries.
+func walkMarkdown(contentFS fs.FS, fn func(filePath string) error) error {
+	return fs.WalkDir(contentFS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || path.Ext(p) != ".md" {
+			return nil
+		}
+		return fn(p)
+	})
+}
+
+// markdownSignature summarizes the markdown files by name, size and
+// modification time, so changes can be detected without reading them.
+func markdownSignature(contentFS fs.FS) (string, error) {
+	var sb strings.Builder
+	err := walkMarkdown(contentFS, func(filePath string) error {
+		info, err := fs.Stat(contentFS, filePath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d;", filePath, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return sb.String(), err
+}
+
+func indexDocument(filePath, content string) (SearchDocument, error) {
+	frontmatter, body := splitFrontmatter(content)
+
+	var meta map[string]any
+	if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+		return SearchDocument{}, err
+	}
+
+	doc := SearchDocument{
+		URL:      docURL(filePath),
+		Headings: markdownHeadings(body),
+		Text:     plainText(body),
+		Meta:     meta,
+	}
+	if title, ok := meta["title"].(string); ok {
+		doc.Title = title
+	}
+	if doc.Title == "" && len(doc.Headings) > 0 {
+		doc.Title = doc.Headings[0]
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSuffix(path.Base(filePath), ".md")
+	}
+	return doc, nil
+}
+
+// docURL returns the URL a markdown file is served at.
+func docURL(filePath string) string {
+	p := strings.TrimSuffix(filePath, ".md")
+	if path.Base(p) == "README" {
+		p = path.Dir(p)
+	}
+	if p == "." {
+		return "/"
+	}
+	return "/" + p
+}
+
+// markdownHeadings returns the text of ATX headings outside code fences.
+func markdownHeadings(body string) []string {
+	var headings []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		if heading != "" {
+			headings = append(headings, heading)
+		}
+	}
+	return headings
+}
+
+var (
+	headingAnchorPattern = regexp.MustCompile(`<a class="heading-anchor"[^>]*>#</a>`)
+	htmlTagPattern       = regexp.MustCompile(`<[^>]*>`)
+	whitespacePattern    = regexp.MustCompile(`\s+`)
+)
+
+// plainText renders markdown to text, dropping directive lines and markup.
+func plainText(body string) string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	text := headingAnchorPattern.ReplaceAllString(renderMarkdown(strings.Join(lines, "\n")), "")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+}
+
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// scoreDocument ranks a document for the query terms. Title matches weigh
+// most, then headings, frontmatter and body text. Documents that do not
+// contain every term score zero.
+func scoreDocument(doc SearchDocument, terms []string) int {
+	title := strings.ToLower(doc.Title)
+	headings := strings.ToLower(strings.Join(doc.Headings, "\n"))
+	meta := strings.ToLower(flattenMeta(doc.Meta))
+	text := strings.ToLower(doc.Text)
+
+	total := 0
+	for _, term := range terms {
+		score := 0
+		if strings.Contains(title, term) {
+			score += 10
+		}
+		if strings.Contains(headings, term) {
+			score += 5
+		}
+		if strings.Contains(meta, term) {
+			score += 3
+		}
+		score += min(strings.Count(text, term), 5)
+
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+func flattenMeta(meta map[string]any) string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "%v\n", meta[key])
+	}
+	return sb.String()
+}
+
+// snippetRadius is the number of bytes of context shown around a match.
+const snippetRadius = 80
+
+// snippet returns text surrounding the first matching term.
+func snippet(text string, terms []string) string {
+	lower := strings.ToLower(text)
+	pos := -1
+	for _, term := range terms {
+		if i := strings.Index(lower, term); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	start, end := max(pos-snippetRadius, 0), min(pos+snippetRadius, len(text))
+	for start > 0 && text[start-1] != ' ' {
+		start--
+	}
+	for end < len(text) && text[end] != ' ' {
+		end++
+	}
+
+	result := strings.TrimSpace(text[start:end])
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(text) {
+		result += "…"
+	}
+	return result
+}
+
+func (m *Module) serveSearch(w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query().Get("q")
+	results := m.search.Search(query)
+	if results == nil {
+		results = []SearchResult{}
+	}
+	for i := range results {
+		results[i].URL = m.url(results[i].URL)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]any{
+		"query":   query,
+		"results": results,
+	})
+}
+
+func (m *Module) serveSearchIndex(w http.ResponseWriter, _ *http.Request) error {
+	docs := slices.Clone(m.search.Documents())
+	for i := range docs {
+		docs[i].URL = m.url(docs[i].URL)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	return writeDocumentsJSON(w, docs)
+}
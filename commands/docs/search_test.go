@@ -0,0 +1,129 @@
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIndex_Documents(t *testing.T) {
+	idx, err := newSearchIndex(fstest.MapFS{
+		"README.md": &fstest.MapFile{Data: []byte("# Welcome\n\nStart here to learn about templates.\n")},
+		"guide/install.md": &fstest.MapFile{Data: []byte(`---
+title: Installation
+---
+## Requirements
+
+You need Go to install the templates tool.
+
+@file example.vuego
+`)},
+		"guide/components.md": &fstest.MapFile{Data: []byte("# Components\n\nComponents are reusable templates.\n\n```\n# not a heading\n```\n")},
+		".hidden/secret.md":   &fstest.MapFile{Data: []byte("# Secret\n")},
+	})
+	require.NoError(t, err)
+
+	docs := idx.Documents()
+	require.Len(t, docs, 3)
+
+	byURL := map[string]SearchDocument{}
+	for _, doc := range docs {
+		byURL[doc.URL] = doc
+	}
+
+	require.Equal(t, "Welcome", byURL["/"].Title)
+	require.Equal(t, "Installation", byURL["/guide/install"].Title)
+	require.Equal(t, []string{"Requirements"}, byURL["/guide/install"].Headings)
+	require.NotContains(t, byURL["/guide/install"].Text, "@file")
+	require.Equal(t, []string{"Components"}, byURL["/guide/components"].Headings)
+}
+
+func TestSearchIndex_Search(t *testing.T) {
+	idx, err := newSearchIndex(fstest.MapFS{
+		"README.md":           &fstest.MapFile{Data: []byte("# Welcome\n\nStart here to learn about templates.\n")},
+		"guide/install.md":    &fstest.MapFile{Data: []byte("---\ntitle: Installation\ntags: setup\n---\nYou need Go to install the templates tool.\n")},
+		"guide/components.md": &fstest.MapFile{Data: []byte("# Components\n\nComponents are reusable templates.\n")},
+	})
+	require.NoError(t, err)
+
+	t.Run("ranks title matches first", func(t *testing.T) {
+		results := idx.Search("components")
+		require.NotEmpty(t, results)
+		require.Equal(t, "/guide/components", results[0].URL)
+	})
+
+	t.Run("matches all terms", func(t *testing.T) {
+		results := idx.Search("install templates")
+		require.Len(t, results, 1)
+		require.Equal(t, "/guide/install", results[0].URL)
+		require.Contains(t, results[0].Snippet, "install")
+	})
+
+	t.Run("matches frontmatter", func(t *testing.T) {
+		results := idx.Search("setup")
+		require.Len(t, results, 1)
+		require.Equal(t, "/guide/install", results[0].URL)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		require.Empty(t, idx.Search("  "))
+	})
+}
+
+func TestSearchIndex_Refresh(t *testing.T) {
+	content := fstest.MapFS{
+		"README.md": &fstest.MapFile{Data: []byte("# Welcome\n")},
+	}
+
+	idx, err := newSearchIndex(content)
+	require.NoError(t, err)
+	require.Empty(t, idx.Search("changelog"))
+
+	content["changelog.md"] = &fstest.MapFile{Data: []byte("# Changelog\n"), ModTime: time.Now()}
+	idx.checked = time.Time{}
+
+	results := idx.Search("changelog")
+	require.Len(t, results, 1)
+	require.Equal(t, "/changelog", results[0].URL)
+}
+
+func TestSearchIndex_InvalidFrontmatter(t *testing.T) {
+	content := fstest.MapFS{
+		"good.md": &fstest.MapFile{Data: []byte("# Good\n")},
+		"bad.md":  &fstest.MapFile{Data: []byte("---\ntitle: [\n---\n# Bad\n")},
+	}
+
+	idx, err := newSearchIndex(content)
+	require.NoError(t, err)
+	require.Len(t, idx.Documents(), 1)
+
+	content["bad.md"] = &fstest.MapFile{Data: []byte("# Fixed\n"), ModTime: time.Now()}
+	content["broken.md"] = &fstest.MapFile{Data: []byte("---\ntitle: [\n---\n"), ModTime: time.Now()}
+	idx.checked = time.Time{}
+
+	require.Len(t, idx.Search("fixed"), 1)
+	require.Len(t, idx.Documents(), 2)
+}
+
+func TestExportSearchIndex(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, ExportSearchIndex(fstest.MapFS{
+		"README.md":         &fstest.MapFile{Data: []byte("# Welcome\n")},
+		"guide/install.md":  &fstest.MapFile{Data: []byte("# Installation\n")},
+		".hidden/secret.md": &fstest.MapFile{Data: []byte("# Secret\n")},
+	}, &buf))
+
+	var docs []SearchDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &docs))
+	require.Len(t, docs, 2)
+}
+
+func TestSnippet(t *testing.T) {
+	text := "one two three four five"
+	require.Equal(t, text, snippet(text, []string{"three"}))
+	require.Equal(t, "one", snippet("one", []string{"missing"}))
+}
@@ -0,0 +1,181 @@
+package docs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	regionStartPattern = regexp.MustCompile(`#region\s+([\w.-]+)`)
+	regionEndPattern   = regexp.MustCompile(`#endregion\b`)
+)
+
+// snippetOptions select part of a file shown by @file.
+type snippetOptions struct {
+	// Region is the name of a `#region name` ... `#endregion` block.
+	Region string
+	// Lines is a 1-based inclusive line range: "10-20", "10-" or "10".
+	Lines string
+	// Highlight lists lines of the shown snippet to highlight: "1,3-5".
+	Highlight []int
+}
+
+// parseSnippetOptions parses key=value directive arguments.
+func parseSnippetOptions(args []string) (snippetOptions, error) {
+	var opts snippetOptions
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return opts, fmt.Errorf("invalid option %q (expected key=value)", arg)
+		}
+
+		switch key {
+		case "region":
+			opts.Region = value
+		case "lines":
+			opts.Lines = value
+		case "highlight":
+			lines, err := parseLineList(value)
+			if err != nil {
+				return opts, fmt.Errorf("highlight: %w", err)
+			}
+			opts.Highlight = lines
+		default:
+			return opts, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return opts, nil
+}
+
+// apply returns the selected part of content.
+func (o snippetOptions) apply(content string) (string, error) {
+	var err error
+	if o.Region != "" {
+		if content, err = extractRegion(content, o.Region); err != nil {
+			return "", err
+		}
+	}
+	if o.Lines != "" {
+		if content, err = selectLines(content, o.Lines); err != nil {
+			return "", err
+		}
+	}
+	return content, nil
+}
+
+// extractRegion returns the lines between `#region name` and its matching
+// `#endregion` marker, in any comment syntax. Nested region markers are
+// dropped and the common indentation is removed.
+func extractRegion(content, name string) (string, error) {
+	lines := strings.Split(content, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if match := regionStartPattern.FindStringSubmatch(line); match != nil && match[1] == name {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("region %q not found", name)
+	}
+
+	var region []string
+	depth := 0
+	for _, line := range lines[start:] {
+		switch {
+		case regionStartPattern.MatchString(line):
+			depth++
+			continue
+		case regionEndPattern.MatchString(line):
+			if depth == 0 {
+				return dedent(region), nil
+			}
+			depth--
+			continue
+		}
+		region = append(region, line)
+	}
+	return "", fmt.Errorf("region %q has no #endregion", name)
+}
+
+// selectLines returns a 1-based inclusive line range of content.
+func selectLines(content, spec string) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	from, to, err := parseLineRange(spec)
+	if err != nil {
+		return "", fmt.Errorf("lines: %w", err)
+	}
+	if to == 0 {
+		to = len(lines)
+	}
+	if from > len(lines) || to > len(lines) {
+		return "", fmt.Errorf("lines %s: file has %d lines", spec, len(lines))
+	}
+	return strings.Join(lines[from-1:to], "\n"), nil
+}
+
+// parseLineRange parses "a-b", "a-" or "a". An open end is returned as 0.
+func parseLineRange(spec string) (int, int, error) {
+	fromStr, toStr, isRange := strings.Cut(spec, "-")
+
+	from, err := strconv.Atoi(fromStr)
+	if err != nil || from < 1 {
+		return 0, 0, fmt.Errorf("invalid line range %q", spec)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+	if toStr == "" {
+		return from, 0, nil
+	}
+
+	to, err := strconv.Atoi(toStr)
+	if err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid line range %q", spec)
+	}
+	return from, to, nil
+}
+
+// parseLineList parses comma-separated line numbers and ranges.
+func parseLineList(spec string) ([]int, error) {
+	var lines []int
+	for _, part := range strings.Split(spec, ",") {
+		from, to, err := parseLineRange(strings.TrimSpace(part))
+		if err != nil || to == 0 {
+			return nil, fmt.Errorf("invalid line list %q", spec)
+		}
+		for line := from; line <= to; line++ {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// dedent removes the indentation shared by all non-blank lines.
+func dedent(lines []string) string {
+	prefix := ""
+	first := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if first {
+			prefix, first = indent, false
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(result, "\n")
+}
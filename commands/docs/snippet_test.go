@@ -0,0 +1,78 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const snippetSource = `package main
+
+// #region setup
+func setup() {
+	// #region inner
+	x := 1
+	// #endregion
+	_ = x
+}
+// #endregion
+
+func main() {}
+`
+
+func TestExtractRegion(t *testing.T) {
+	region, err := extractRegion(snippetSource, "setup")
+	require.NoError(t, err)
+	require.Equal(t, "func setup() {\n\tx := 1\n\t_ = x\n}", region)
+
+	region, err = extractRegion(snippetSource, "inner")
+	require.NoError(t, err)
+	require.Equal(t, "x := 1", region)
+
+	_, err = extractRegion(snippetSource, "missing")
+	require.ErrorContains(t, err, `region "missing" not found`)
+
+	_, err = extractRegion("// #region open\nx\n", "open")
+	require.ErrorContains(t, err, "no #endregion")
+}
+
+func TestSelectLines(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\n"
+
+	tests := map[string]string{
+		"2":   "two",
+		"2-3": "two\nthree",
+		"3-":  "three\nfour",
+	}
+	for spec, want := range tests {
+		got, err := selectLines(content, spec)
+		require.NoError(t, err, spec)
+		require.Equal(t, want, got, spec)
+	}
+
+	for _, spec := range []string{"0", "3-2", "x", "2-9"} {
+		_, err := selectLines(content, spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestParseSnippetOptions(t *testing.T) {
+	opts, err := parseSnippetOptions([]string{"region=setup", "lines=2-3", "highlight=1,3-4"})
+	require.NoError(t, err)
+	require.Equal(t, snippetOptions{Region: "setup", Lines: "2-3", Highlight: []int{1, 3, 4}}, opts)
+
+	_, err = parseSnippetOptions([]string{"region"})
+	require.Error(t, err)
+	_, err = parseSnippetOptions([]string{"colour=red"})
+	require.Error(t, err)
+	_, err = parseSnippetOptions([]string{"highlight=2-"})
+	require.Error(t, err)
+}
+
+func TestHighlightLines(t *testing.T) {
+	require.Equal(t, "a &lt; b\nc", highlightLines("a < b\nc", nil))
+	require.Equal(t,
+		`<span class="code-line">a &lt; b</span>`+"\n"+`<span class="code-line highlighted">c</span>`,
+		highlightLines("a < b\nc", []int{2}),
+	)
+}
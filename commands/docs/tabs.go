@@ -6,10 +6,15 @@ import (
 	"strings"
 )
 
-var tabGroupCounter int
-
 // TabGroup represents a group of tabs.
 type TabGroup struct {
+	// ID prefixes the tab and panel element IDs. It is assigned from the
+	// page path and the position of the group on the page.
+	ID string
+	// Name links tab groups: selecting a tab selects the tab with the same
+	// label in all groups of that name, and the choice persists across
+	// pages. Unnamed groups are independent.
+	Name string
 	Tabs []Tab
 }
 
@@ -19,6 +24,17 @@ type Tab struct {
 	Content string
 	IsCode  bool
 	Mode    string // Ace editor mode (html, yaml, json, etc.)
+
+	// Highlight lists 1-based lines of code tabs to highlight.
+	Highlight []int
+	// Editor names the editable example field a code tab edits
+	// ("template" or "data"). Editable tabs render as an Ace editor.
+	Editor string
+}
+
+// tabGroupID returns the ID of the n-th tab group on a page.
+func tabGroupID(docPath string, n int) string {
+	return fmt.Sprintf("%s-tabs-%d", slugify(docPath), n)
 }
 
 func (m *Module) renderTabGroup(tg *TabGroup) string {
@@ -26,13 +42,16 @@ func (m *Module) renderTabGroup(tg *TabGroup) string {
 		return ""
 	}
 
-	tabGroupCounter++
-	groupID := tabGroupCounter
+	groupID := html.EscapeString(tg.ID)
 
 	var sb strings.Builder
 	sb.WriteString(`<div class="relative my-6">`)
 	sb.WriteString(`<div class="ring-offset-background focus-visible:outline-none focus-visible:ring-2 focus-visible:ring-ring focus-visible:ring-offset-2 relative rounded-md border">`)
-	sb.WriteString(`<div class="tabs">`)
+	if tg.Name != "" {
+		sb.WriteString(fmt.Sprintf(`<div class="tabs" id="%s" data-tab-group="%s">`, groupID, html.EscapeString(tg.Name)))
+	} else {
+		sb.WriteString(fmt.Sprintf(`<div class="tabs" id="%s">`, groupID))
+	}
 	sb.WriteString(`<div role="tablist">`)
 
 	for i, tab := range tg.Tabs {
@@ -43,8 +62,8 @@ func (m *Module) renderTabGroup(tg *TabGroup) string {
 			tabindex = "0"
 		}
 		sb.WriteString(fmt.Sprintf(
-			`<button role="tab" aria-controls="panel-%d-%d" aria-selected="%s" tabindex="%s">%s</button>`,
-			groupID, i, selected, tabindex, html.EscapeString(tab.Label),
+			`<button role="tab" id="%s-tab-%d" aria-controls="%s-panel-%d" aria-selected="%s" tabindex="%s" data-tab="%s">%s</button>`,
+			groupID, i, groupID, i, selected, tabindex, html.EscapeString(tab.Label), html.EscapeString(tab.Label),
 		))
 	}
 	sb.WriteString(`</div>`)
@@ -54,7 +73,7 @@ func (m *Module) renderTabGroup(tg *TabGroup) string {
 		if i > 0 {
 			hidden = " hidden"
 		}
-		sb.WriteString(fmt.Sprintf(`<section id="panel-%d-%d" role="tabpanel"%s>`, groupID, i, hidden))
+		sb.WriteString(fmt.Sprintf(`<section id="%s-panel-%d" role="tabpanel" aria-labelledby="%s-tab-%d"%s>`, groupID, i, groupID, i, hidden))
 
 		sb.WriteString(m.renderSingleTab(tab))
 
@@ -73,11 +92,47 @@ func (m *Module) renderSingleTab(tab Tab) string {
 		if mode == "" {
 			mode = "text"
 		}
+		if tab.Editor != "" {
+			return fmt.Sprintf(
+				`<div class="min-h-[150px] w-full" data-example-editor="%s" data-mode="%s">%s</div>`,
+				html.EscapeString(tab.Editor),
+				html.EscapeString(mode),
+				html.EscapeString(tab.Content),
+			)
+		}
+		code, highlighted := highlightCode(tab.Content, mode, tab.Highlight)
+		class := "language-" + html.EscapeString(mode)
+		if highlighted {
+			class += " chroma"
+		}
 		return fmt.Sprintf(
-			`<pre class="grid text-sm min-h-[150px] max-h-[650px] overflow-y-auto rounded-xl scrollbar"><code class="language-%s !bg-muted/40 !p-3.5">%s</code></pre>`,
-			html.EscapeString(mode),
-			html.EscapeString(tab.Content),
+			`<pre class="grid text-sm min-h-[150px] max-h-[650px] overflow-y-auto rounded-xl scrollbar"><code class="%s !bg-muted/40 !p-3.5">%s</code></pre>`,
+			class,
+			code,
 		)
 	}
 	return fmt.Sprintf(`<div class="preview flex min-h-[150px] max-h-[650px] w-full justify-center p-10 items-center">%s</div>`, tab.Content)
 }
+
+// highlightLines escapes code for HTML. With highlighted lines, every line
+// is wrapped in a code-line span so highlighted ones can be styled.
+func highlightLines(code string, highlight []int) string {
+	if len(highlight) == 0 {
+		return html.EscapeString(code)
+	}
+
+	marked := make(map[int]bool, len(highlight))
+	for _, line := range highlight {
+		marked[line] = true
+	}
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		class := "code-line"
+		if marked[i+1] {
+			class += " highlighted"
+		}
+		lines[i] = fmt.Sprintf(`<span class="%s">%s</span>`, class, html.EscapeString(line))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,80 @@
+package docs
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+const tabsDoc = `# Install
+
+@tabs language
+@file "Go" main.go
+@file "YAML" data.yml
+
+Between.
+
+@tabs
+@file "Go" main.go
+
+@tabs language
+@file "Go" main.go
+@file "YAML" data.yml
+`
+
+func newTabsModule() *Module {
+	return NewModule(fstest.MapFS{
+		"guide/main.go":  &fstest.MapFile{Data: []byte("package main\n")},
+		"guide/data.yml": &fstest.MapFile{Data: []byte("a: 1\n")},
+	}, WithStrict(true))
+}
+
+func TestParseDirectives_TabGroupIDs(t *testing.T) {
+	m := newTabsModule()
+
+	doc, err := m.parseDirectives(context.Background(), "guide/install.md", tabsDoc, 0)
+	require.NoError(t, err)
+
+	require.Contains(t, doc.Content, `<div class="tabs" id="guide-install-md-tabs-1" data-tab-group="language">`)
+	require.Contains(t, doc.Content, `<div class="tabs" id="guide-install-md-tabs-2">`)
+	require.Contains(t, doc.Content, `<div class="tabs" id="guide-install-md-tabs-3" data-tab-group="language">`)
+	require.Contains(t, doc.Content, `<button role="tab" id="guide-install-md-tabs-1-tab-1" aria-controls="guide-install-md-tabs-1-panel-1" aria-selected="false" tabindex="-1" data-tab="YAML">YAML</button>`)
+	require.Contains(t, doc.Content, `<section id="guide-install-md-tabs-1-panel-1" role="tabpanel" aria-labelledby="guide-install-md-tabs-1-tab-1" hidden>`)
+	require.Equal(t, []string{"partials/tab-groups.vuego"}, doc.Partials)
+
+	again, err := m.parseDirectives(context.Background(), "guide/install.md", tabsDoc, 0)
+	require.NoError(t, err)
+	require.Equal(t, doc.Content, again.Content)
+}
+
+func TestParseDirectives_TabGroupIDsConcurrent(t *testing.T) {
+	m := newTabsModule()
+
+	want, err := m.parseDirectives(context.Background(), "guide/install.md", tabsDoc, 0)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]string, 32)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc, err := m.parseDirectives(context.Background(), "guide/install.md", tabsDoc, 0)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = doc.Content
+		}()
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		require.NoError(t, errs[i])
+		require.Equal(t, want.Content, got)
+	}
+}
@@ -0,0 +1,174 @@
+package docs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+
+	chi "github.com/go-chi/chi/v5"
+)
+
+// tagsURL is the index of all page tags. Every tag is listed on its own
+// page below it. The generated pages take precedence over content in a
+// tags directory.
+const tagsURL = "/tags/"
+
+// tagURL returns the path of the page listing the pages tagged tag.
+func tagURL(tag string) string {
+	return tagsURL + slugify(tag)
+}
+
+// pageTag is a tag with the pages carrying it.
+type pageTag struct {
+	Name  string
+	Slug  string
+	Pages []feedPage
+}
+
+// tags returns the tags of all pages, sorted by name. The pages of a tag
+// are sorted newest first, then by title.
+func (m *Module) tags() ([]pageTag, error) {
+	pages, err := m.feedPages()
+	if err != nil {
+		return nil, err
+	}
+
+	bySlug := make(map[string]*pageTag)
+	for _, page := range pages {
+		seen := make(map[string]bool)
+		for _, name := range page.Meta.Tags {
+			slug := slugify(name)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+
+			tag, ok := bySlug[slug]
+			if !ok {
+				tag = &pageTag{Name: name, Slug: slug}
+				bySlug[slug] = tag
+			}
+			tag.Pages = append(tag.Pages, page)
+		}
+	}
+
+	tags := make([]pageTag, 0, len(bySlug))
+	for _, tag := range bySlug {
+		sort.SliceStable(tag.Pages, func(i, j int) bool {
+			a, b := tag.Pages[i], tag.Pages[j]
+			if !a.Updated.Equal(b.Updated) {
+				return a.Updated.After(b.Updated)
+			}
+			return a.Title < b.Title
+		})
+		tags = append(tags, *tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Slug < tags[j].Slug
+	})
+	return tags, nil
+}
+
+// tagLinks returns the name and URL of every tag, for the tags of a page.
+func (m *Module) tagLinks(names []string) []any {
+	var links []any
+	for _, name := range names {
+		if slugify(name) == "" {
+			continue
+		}
+		links = append(links, map[string]any{
+			"name": name,
+			"url":  m.url(tagURL(name)),
+		})
+	}
+	return links
+}
+
+func (m *Module) serveTags(w http.ResponseWriter, r *http.Request) error {
+	tags, err := m.tags()
+	if err != nil {
+		return err
+	}
+
+	var items []any
+	for _, tag := range tags {
+		items = append(items, map[string]any{
+			"name":  tag.Name,
+			"url":   m.url(tagURL(tag.Name)),
+			"count": len(tag.Pages),
+		})
+	}
+
+	data := map[string]any{
+		"title": "Tags",
+		"tags":  items,
+	}
+	return m.renderTagLayout(r.Context(), w, "layouts/tags.vuego", tagsURL, data)
+}
+
+func (m *Module) serveTag(w http.ResponseWriter, r *http.Request) error {
+	slug := chi.URLParam(r, "tag")
+
+	tags, err := m.tags()
+	if err != nil {
+		return err
+	}
+	i := sort.Search(len(tags), func(i int) bool {
+		return tags[i].Slug >= slug
+	})
+	if i == len(tags) || tags[i].Slug != slug {
+		return notFound(fmt.Errorf("tag not found: %s", slug))
+	}
+	tag := tags[i]
+
+	var pages []any
+	for _, page := range tag.Pages {
+		item := map[string]any{
+			"title":   page.Title,
+			"url":     page.URL,
+			"summary": page.Summary,
+		}
+		if !page.Meta.Date.IsZero() {
+			item["date"] = page.Meta.Date.Format("2006-01-02")
+		}
+		pages = append(pages, item)
+	}
+
+	description := "1 page"
+	if len(pages) != 1 {
+		description = strconv.Itoa(len(pages)) + " pages"
+	}
+
+	data := map[string]any{
+		"title":       tag.Name,
+		"description": description,
+		"tag":         tag.Name,
+		"tags_url":    m.url(tagsURL),
+		"pages":       pages,
+	}
+	return m.renderTagLayout(r.Context(), w, "layouts/tag.vuego", tagURL(tag.Name), data)
+}
+
+// renderTagLayout renders a tag listing through layout. The basecoat
+// layouts can be overridden from the content tree.
+func (m *Module) renderTagLayout(ctx context.Context, w http.ResponseWriter, layout, pageURL string, data map[string]any) error {
+	data["search"] = m.url(searchURL)
+	if m.feedDir != "" {
+		data["feed"] = m.url(atomURL)
+	}
+	m.fill(&data)
+	m.fillLocales(data, pageURL)
+
+	var buf bytes.Buffer
+	if err := m.vuego.Load(layout).Fill(data).Render(ctx, &buf); err != nil {
+		return fmt.Errorf("rendering layout: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.Copy(w, &buf)
+	return nil
+}
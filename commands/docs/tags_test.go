@@ -0,0 +1,55 @@
+package docs
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	tags, err := NewModule(fstest.MapFS{
+		"install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\ntags: [Getting Started, cli]\n---\n")},
+		"usage.md":   &fstest.MapFile{Data: []byte("---\ntitle: Usage\ndate: 2024-02-01\ntags: [CLI, cli]\n---\n")},
+		"draft.md":   &fstest.MapFile{Data: []byte("---\ntitle: Draft\ndraft: true\ntags: [cli, wip]\n---\n")},
+		"plain.md":   &fstest.MapFile{Data: []byte("# Plain\n")},
+	}).tags()
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	require.Equal(t, "cli", tags[0].Slug)
+	require.Len(t, tags[0].Pages, 2)
+	require.Equal(t, "Usage", tags[0].Pages[0].Title)
+	require.Equal(t, "Install", tags[0].Pages[1].Title)
+
+	require.Equal(t, "Getting Started", tags[1].Name)
+	require.Equal(t, "getting-started", tags[1].Slug)
+}
+
+func TestTags_Drafts(t *testing.T) {
+	tags, err := NewModule(fstest.MapFS{
+		"install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\ntags: [cli]\n---\n")},
+		"draft.md":   &fstest.MapFile{Data: []byte("---\ntitle: Draft\ndraft: true\ntags: [cli, wip]\n---\n")},
+	}, WithDrafts(true)).tags()
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	require.Len(t, tags[0].Pages, 2)
+	require.Equal(t, "wip", tags[1].Slug)
+}
+
+func TestTagLinks(t *testing.T) {
+	m := NewModule(fstest.MapFS{})
+	m.basePath = "/v/2"
+	require.Equal(t, []any{
+		map[string]any{"name": "Getting Started", "url": "/v/2/tags/getting-started"},
+	}, m.tagLinks([]string{"Getting Started", "!"}))
+}
+
+func TestTags_Redirect(t *testing.T) {
+	rec := serveFeed(t, NewModule(fstest.MapFS{
+		"install.md": &fstest.MapFile{Data: []byte("---\ntitle: Install\ntags: [cli]\n---\n")},
+	}), "/tags")
+	require.Equal(t, http.StatusMovedPermanently, rec.Code)
+	require.Equal(t, "/tags/", rec.Header().Get("Location"))
+}
@@ -0,0 +1,110 @@
+package docs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Headings at these levels are listed in the table of contents. Level 1
+// is left out as it usually repeats the page title.
+const (
+	tocMinLevel = 2
+	tocMaxLevel = 4
+)
+
+// heading is a rendered markdown heading.
+type heading struct {
+	Level int
+	ID    string
+	Label string
+}
+
+// headingIDs assigns unique slug IDs to the headings of a page and
+// collects them for the table of contents. A page renders its markdown in
+// several chunks between directives, so one headingIDs is shared by all.
+type headingIDs struct {
+	seen     map[string]bool
+	headings []heading
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{seen: make(map[string]bool)}
+}
+
+// add registers a heading and returns its ID. The explicit ID is used if
+// set, otherwise the ID is a slug of the label. Duplicates get a numeric
+// suffix, so the first occurrence keeps the plain slug.
+func (h *headingIDs) add(level int, label, explicit string) string {
+	base := explicit
+	if base == "" {
+		base = slugify(label)
+	}
+	if base == "" {
+		base = "section"
+	}
+
+	id := base
+	for n := 1; h.seen[id]; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	h.seen[id] = true
+
+	h.headings = append(h.headings, heading{Level: level, ID: id, Label: label})
+	return id
+}
+
+// toc returns the collected headings as nested items with id, label and
+// children keys, in the shape the toc.vuego partial renders.
+func (h *headingIDs) toc() []any {
+	type entry struct {
+		level int
+		item  map[string]any
+	}
+
+	var root []any
+	var stack []entry
+	for _, hd := range h.headings {
+		if hd.Level < tocMinLevel || hd.Level > tocMaxLevel {
+			continue
+		}
+
+		item := map[string]any{
+			"id":    hd.ID,
+			"label": hd.Label,
+		}
+		for len(stack) > 0 && stack[len(stack)-1].level >= hd.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			root = append(root, item)
+		} else {
+			parent := stack[len(stack)-1].item
+			children, _ := parent["children"].([]any)
+			parent["children"] = append(children, item)
+		}
+		stack = append(stack, entry{level: hd.Level, item: item})
+	}
+	return root
+}
+
+// slugify lowercases s and joins its letters and digits with dashes.
+func slugify(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		case r == '\'' || r == '’':
+			// Drop apostrophes so "don't" becomes "dont".
+		default:
+			dash = true
+		}
+	}
+	return sb.String()
+}
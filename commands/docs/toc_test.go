@@ -0,0 +1,63 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Getting Started":     "getting-started",
+		"  API: v1 / Render ": "api-v1-render",
+		"Don't panic!":        "dont-panic",
+		"Über <code>":         "über-code",
+		"---":                 "",
+	}
+	for in, want := range tests {
+		require.Equal(t, want, slugify(in), in)
+	}
+}
+
+func TestHeadingIDs_Unique(t *testing.T) {
+	h := newHeadingIDs()
+	require.Equal(t, "usage", h.add(2, "Usage", ""))
+	require.Equal(t, "usage-1", h.add(2, "Usage", ""))
+	require.Equal(t, "usage-2", h.add(3, "Usage", ""))
+	require.Equal(t, "section", h.add(2, "!!", ""))
+	require.Equal(t, "custom", h.add(2, "Usage", "custom"))
+}
+
+func TestRenderMarkdownHeadings(t *testing.T) {
+	h := newHeadingIDs()
+
+	out := renderMarkdownHeadings("# Title\n\n## Install `vuego`\n\ntext\n", h)
+	require.Contains(t, out, `<h2 id="install-vuego">Install <code class="highlight">vuego</code><a class="heading-anchor" href="#install-vuego" aria-label="Link to this section">#</a></h2>`)
+
+	// A later chunk of the same page continues the numbering.
+	out = renderMarkdownHeadings("## Install vuego\n", h)
+	require.Contains(t, out, `id="install-vuego-1"`)
+}
+
+func TestHeadingIDs_TOC(t *testing.T) {
+	h := newHeadingIDs()
+	renderMarkdownHeadings("# Page\n\n## One\n\n### One A\n\n#### Deep\n\n##### Too deep\n\n### One B\n\n## Two\n", h)
+
+	require.Equal(t, []any{
+		map[string]any{
+			"id":    "one",
+			"label": "One",
+			"children": []any{
+				map[string]any{
+					"id":    "one-a",
+					"label": "One A",
+					"children": []any{
+						map[string]any{"id": "deep", "label": "Deep"},
+					},
+				},
+				map[string]any{"id": "one-b", "label": "One B"},
+			},
+		},
+		map[string]any{"id": "two", "label": "Two"},
+	}, h.toc())
+}
@@ -0,0 +1,150 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/titpetric/platform"
+)
+
+// Version is a documentation release served under /v/{name}/.
+type Version struct {
+	// Name is the version in URLs, for example "v1.2".
+	Name string
+	// Label is shown in the version switcher. It defaults to Name.
+	Label string
+	// FS holds the content of the version, for example a directory with
+	// a git ref exported into it.
+	FS fs.FS
+	// Dir is the directory FS reads from, if any. See WithContentDir.
+	Dir string
+}
+
+// WithVersions serves each version under /v/{name}/ and redirects
+// unversioned URLs to the default version.
+func WithVersions(defaultVersion string, versions ...Version) ModuleOption {
+	return func(m *Module) {
+		m.defaultVersion = defaultVersion
+		m.versions = versions
+	}
+}
+
+// ParseVersion parses a version flag value of the form name=dir or
+// name:label=dir. The content of the version is read from dir.
+func ParseVersion(value string) (Version, error) {
+	name, dir, ok := strings.Cut(value, "=")
+	if !ok || name == "" || dir == "" {
+		return Version{}, fmt.Errorf("invalid version %q (expected name=dir)", value)
+	}
+
+	name, label, _ := strings.Cut(name, ":")
+	if strings.ContainsAny(name, "/ ") {
+		return Version{}, fmt.Errorf("invalid version name %q", name)
+	}
+	return Version{Name: name, Label: label, FS: os.DirFS(dir), Dir: dir}, nil
+}
+
+func versionPath(name string) string {
+	return "/v/" + name
+}
+
+// url returns the URL of a docs path, which starts with a slash, within
+// the version the module serves.
+func (m *Module) url(p string) string {
+	return m.basePath + p
+}
+
+// mountVersions mounts a module per version and redirects unversioned
+// page URLs to the default version.
+func (m *Module) mountVersions(ctx context.Context, r platform.Router) error {
+	known := false
+	for _, v := range m.versions {
+		known = known || v.Name == m.defaultVersion
+	}
+	if !known {
+		return fmt.Errorf("default version %q is not configured", m.defaultVersion)
+	}
+
+	for _, v := range m.versions {
+		vm := m.derive(v.FS)
+		vm.version = v.Name
+		vm.basePath = versionPath(v.Name)
+		vm.contentDir = v.Dir
+
+		router := chi.NewRouter()
+		if err := vm.Mount(ctx, router); err != nil {
+			return fmt.Errorf("mounting version %s: %w", v.Name, err)
+		}
+
+		prefix := versionPath(v.Name)
+		r.Handle(prefix+"/*", http.StripPrefix(prefix, router))
+		r.Get(prefix, redirectTo(prefix+"/"))
+	}
+
+	r.Get("/assets/*", http.FileServer(http.FS(m.FS)).ServeHTTP)
+	r.Get("/*", func(w http.ResponseWriter, r *http.Request) {
+		target := versionPath(m.defaultVersion) + r.URL.Path
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+	return nil
+}
+
+func redirectTo(target string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	}
+}
+
+// fillVersions sets the version and versions data for the switcher.
+func (m *Module) fillVersions(dest *map[string]any) {
+	if m.version == "" {
+		return
+	}
+	if *dest == nil {
+		*dest = map[string]any{}
+	}
+
+	var versions []any
+	for _, v := range m.versions {
+		label := v.Label
+		if label == "" {
+			label = v.Name
+		}
+		versions = append(versions, map[string]any{
+			"name":    v.Name,
+			"label":   label,
+			"url":     versionPath(v.Name) + "/",
+			"current": v.Name == m.version,
+			"default": v.Name == m.defaultVersion,
+		})
+	}
+
+	(*dest)["version"] = m.version
+	(*dest)["versions"] = versions
+}
+
+// prefixMenu prefixes the internal URLs of menu items with the version path.
+func (m *Module) prefixMenu(menu []any) {
+	if m.basePath == "" {
+		return
+	}
+	for _, g := range menu {
+		group, _ := g.(map[string]any)
+		items, _ := group["items"].([]any)
+		for _, item := range items {
+			entry, _ := item.(map[string]any)
+			link, _ := entry["url"].(string)
+			if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") && !strings.HasPrefix(link, m.basePath+"/") {
+				entry["url"] = m.url(link)
+			}
+		}
+	}
+}
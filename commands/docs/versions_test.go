@@ -0,0 +1,144 @@
+package docs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	chi "github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func newVersionedRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	m := NewModule(fstest.MapFS{}, WithVersions("v2",
+		Version{Name: "v1", FS: fstest.MapFS{
+			"guide.md": &fstest.MapFile{Data: []byte("# Guide\n\nLegacy install steps.\n")},
+		}},
+		Version{Name: "v2", Label: "2.x (latest)", FS: fstest.MapFS{
+			"guide.md": &fstest.MapFile{Data: []byte("# Guide\n\nCurrent install steps.\n")},
+		}},
+	))
+
+	r := chi.NewRouter()
+	require.NoError(t, m.Mount(context.Background(), r))
+	return r
+}
+
+func TestVersions_Redirect(t *testing.T) {
+	r := newVersionedRouter(t)
+
+	tests := map[string]string{
+		"/":              "/v/v2/",
+		"/guide":         "/v/v2/guide",
+		"/search?q=step": "/v/v2/search?q=step",
+		"/v/v1":          "/v/v1/",
+	}
+	for target, location := range tests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		require.Contains(t, []int{http.StatusFound, http.StatusMovedPermanently}, rec.Code, target)
+		require.Equal(t, location, rec.Header().Get("Location"), target)
+	}
+}
+
+func TestVersions_Search(t *testing.T) {
+	r := newVersionedRouter(t)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v/v1/search?q=legacy", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Results []SearchResult `json:"results"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Results, 1)
+	require.Equal(t, "/v/v1/guide", resp.Results[0].URL)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v/v2/search?q=legacy", nil))
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Empty(t, resp.Results)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v/v1/search.json", nil))
+	var docs []SearchDocument
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&docs))
+	require.Len(t, docs, 1)
+	require.Equal(t, "/v/v1/guide", docs[0].URL)
+}
+
+func TestVersions_UnknownDefault(t *testing.T) {
+	m := NewModule(fstest.MapFS{}, WithVersions("v3", Version{Name: "v1", FS: fstest.MapFS{}}))
+	require.Error(t, m.Mount(context.Background(), chi.NewRouter()))
+}
+
+func TestVersions_Data(t *testing.T) {
+	m := NewModule(fstest.MapFS{
+		"data/menu.yml": &fstest.MapFile{Data: []byte("menu:\n  - type: group\n    label: Links\n    items:\n      - label: Guide\n        url: /guide\n      - label: GitHub\n        url: https://github.com\n")},
+	}, WithMenuMode(MenuManual), WithVersions("v1", Version{Name: "v1"}, Version{Name: "v2"}))
+	m.version = "v2"
+	m.basePath = versionPath("v2")
+
+	var data map[string]any
+	m.fill(&data)
+
+	require.Equal(t, "v2", data["version"])
+	require.Equal(t, []any{
+		map[string]any{"name": "v1", "label": "v1", "url": "/v/v1/", "current": false, "default": true},
+		map[string]any{"name": "v2", "label": "v2", "url": "/v/v2/", "current": true, "default": false},
+	}, data["versions"])
+
+	items := data["menu"].([]any)[0].(map[string]any)["items"].([]any)
+	require.Equal(t, "/v/v2/guide", items[0].(map[string]any)["url"])
+	require.Equal(t, "https://github.com", items[1].(map[string]any)["url"])
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("v1:1.x (legacy)=docs/v1")
+	require.NoError(t, err)
+	require.Equal(t, "v1", v.Name)
+	require.Equal(t, "1.x (legacy)", v.Label)
+	require.Equal(t, "docs/v1", v.Dir)
+
+	for _, value := range []string{"v1", "=docs", "v1=", "a/b=docs"} {
+		_, err := ParseVersion(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestVersions_PageSearchURL(t *testing.T) {
+	m := NewModule(fstest.MapFS{}, WithVersions("v2", Version{Name: "v1"}, Version{Name: "v2"}))
+
+	vm := m.derive(fstest.MapFS{
+		"guide.md": &fstest.MapFile{Data: []byte("# Guide\n")},
+	})
+	vm.version = "v1"
+	vm.basePath = versionPath("v1")
+
+	data := vm.docData("guide.md", DocMeta{Title: "Guide"}, parsedDoc{})
+	require.Equal(t, "/v/v1/search", data["search"])
+	require.Equal(t, "v1", data["version"])
+}
+
+func TestVersions_DerivedDirectives(t *testing.T) {
+	m := NewModule(fstest.MapFS{}, WithVersions("v1", Version{Name: "v1", FS: fstest.MapFS{}}))
+	require.NoError(t, m.RegisterDirective(Directive{
+		Name: "api",
+		Render: func(context.Context, *DirectiveContext) (DirectiveOutput, error) {
+			return DirectiveOutput{}, nil
+		},
+	}))
+
+	d := m.derive(fstest.MapFS{})
+	require.Contains(t, d.directives, "api")
+
+	delete(d.directives, "api")
+	require.Contains(t, m.directives, "api", "registries are copied")
+}
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io/fs"
 	"net/http"
-	"strings"
 	"testing/fstest"
 
 	"github.com/titpetric/vuego"
@@ -14,187 +14,314 @@ import (
 )
 
 // RenderRequest contains template and data for rendering.
+// Name is the template file name, used to find sidecar assets in Files.
+// Output selects the output format (see OutputHTML and friends).
 type RenderRequest struct {
+	Name     string            `json:"name,omitempty"`
 	Template string            `json:"template"`
 	Data     string            `json:"data"`
 	Files    map[string]string `json:"files,omitempty"`
+	Output   string            `json:"output,omitempty"`
 }
 
-// RenderResponse contains the rendered HTML or an error.
+// RenderResponse contains the rendered output or an error.
+// HTML holds the output in the requested format, which is YAML for OutputYAML.
 type RenderResponse struct {
 	HTML  string `json:"html,omitempty"`
 	Error string `json:"error,omitempty"`
 }
 
+// RenderOption configures the render handler behavior.
+type RenderOption func(*renderConfig)
+
+type renderConfig struct {
+	loadOptions []vuego.LoadOption
+	limits      Limits
+	limiter     *rateLimiter
+	// slots holds a token for every running render, see Limits.MaxRenders.
+	slots chan struct{}
+}
+
+// WithRenderLoadOption adds a LoadOption to the render handler's Vue instance.
+func WithRenderLoadOption(opt ...vuego.LoadOption) RenderOption {
+	return func(cfg *renderConfig) {
+		cfg.loadOptions = append(cfg.loadOptions, opt...)
+	}
+}
+
+// WithLimits replaces the DefaultLimits used by the render handler.
+func WithLimits(limits Limits) RenderOption {
+	return func(cfg *renderConfig) {
+		cfg.limits = limits
+	}
+}
+
+// WithRateLimit limits each client (by remote address) to perSecond requests,
+// allowing bursts of up to burst requests.
+func WithRateLimit(perSecond float64, burst int) RenderOption {
+	return func(cfg *renderConfig) {
+		cfg.limiter = newRateLimiter(perSecond, burst)
+	}
+}
+
 // RenderHandler returns an http.HandlerFunc that renders templates via POST /render.
 // The optional baseFS provides additional files (like components) available during rendering.
+// The handler applies DefaultLimits; use NewRenderHandler to configure them.
 func RenderHandler(baseFS fs.FS, opts ...vuego.LoadOption) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-
-		if r.Method != http.MethodPost {
-			_ = json.NewEncoder(w).Encode(RenderResponse{
-				Error: "method not allowed",
-			})
-			return
-		}
-
-		var req RenderRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			_ = json.NewEncoder(w).Encode(RenderResponse{
-				Error: "invalid JSON: " + err.Error(),
-			})
-			return
-		}
+	return NewRenderHandler(baseFS, WithRenderLoadOption(opts...)).ServeHTTP
+}
 
-		html, err := Render(r.Context(), baseFS, req, opts...)
-		if err != nil {
-			_ = json.NewEncoder(w).Encode(RenderResponse{
-				Error: err.Error(),
-			})
-			return
-		}
+// NewRenderHandler returns an http.Handler that renders templates via POST /render.
+// Request size, file count and render time are bounded by the configured Limits,
+// and failures are reported with a matching 4xx/5xx status code.
+func NewRenderHandler(baseFS fs.FS, opts ...RenderOption) http.Handler {
+	return newRenderHandler(baseFS, opts...)
+}
 
-		_ = json.NewEncoder(w).Encode(RenderResponse{
-			HTML: html,
-		})
+func newRenderHandler(baseFS fs.FS, opts ...RenderOption) *renderHandler {
+	cfg := &renderConfig{
+		limits: DefaultLimits,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.limits.MaxRenders > 0 {
+		cfg.slots = make(chan struct{}, cfg.limits.MaxRenders)
+	}
+	return &renderHandler{
+		fs:     baseFS,
+		config: cfg,
 	}
 }
 
-// Render processes a RenderRequest and returns rendered HTML.
-// This function can be used by both HTTP handlers and CLI commands.
-// If the template specifies a layout in frontmatter, Layout() is used instead of Render().
-// This function automatically injects style links for adjacent .less/.css files.
-func Render(ctx context.Context, baseFS fs.FS, req RenderRequest, opts ...vuego.LoadOption) (string, error) {
-	// Parse data (supports both JSON and YAML)
-	var data map[string]any
-	if req.Data != "" {
-		if err := yaml.Unmarshal([]byte(req.Data), &data); err != nil {
-			return "", err
-		}
+type renderHandler struct {
+	fs     fs.FS
+	config *renderConfig
+}
+
+func (h *renderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := h.admit(w, r); err != nil {
+		writeRenderResponse(w, StatusCode(err), RenderResponse{
+			Error: err.Error(),
+		})
+		return
 	}
-	if data == nil {
-		data = make(map[string]any)
+
+	var req RenderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeRenderResponse(w, decodeStatus(err), RenderResponse{
+			Error: "invalid JSON: " + err.Error(),
+		})
+		return
 	}
 
-	// Build filesystem with template and any additional files
-	templateFS := buildTemplateFS(baseFS, req.Files, req.Template)
+	if err := validateFiles(req.Files, h.config.limits.MaxFiles); err != nil {
+		writeRenderResponse(w, StatusCode(err), RenderResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 
-	// Wrap template with injected styles
-	templateWithStyles := injectStyleLinksIntoTemplate(req.Files, req.Template)
+	ctx, cancel := h.context(r)
+	defer cancel()
 
-	// Re-build filesystem with updated template
-	templateFS = buildTemplateFS(baseFS, req.Files, templateWithStyles)
+	html, err := Render(ctx, h.fs, req, h.config.loadOptions...)
+	if err != nil {
+		writeRenderResponse(w, StatusCode(err), RenderResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 
-	// Render the template
-	renderer := vuego.NewFS(templateFS, opts...)
-	tpl := renderer.Load("template.html").Fill(data)
+	writeRenderResponse(w, http.StatusOK, RenderResponse{
+		HTML: html,
+	})
+}
 
-	var buf bytes.Buffer
+// admit applies the method check, rate limit and body size limit to a request.
+func (h *renderHandler) admit(w http.ResponseWriter, r *http.Request) error {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return statusError{status: http.StatusMethodNotAllowed, err: errors.New("method not allowed")}
+	}
 
-	if err := tpl.Render(ctx, &buf); err != nil {
-		return "", err
+	if h.config.limiter != nil && !h.config.limiter.allow(clientKey(r)) {
+		w.Header().Set("Retry-After", "1")
+		return statusError{status: http.StatusTooManyRequests, err: errors.New("rate limit exceeded")}
 	}
 
-	return buf.String(), nil
+	if h.config.limits.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.config.limits.MaxBodyBytes)
+	}
+	return nil
 }
 
-// buildTemplateFS creates a filesystem combining the base FS with request files.
-func buildTemplateFS(baseFS fs.FS, files map[string]string, template string) fs.FS {
-	primary := fstest.MapFS{
-		"template.html": &fstest.MapFile{Data: []byte(template)},
+// context returns the request context bounded by the configured render
+// timeout, carrying the render slots of the handler.
+func (h *renderHandler) context(r *http.Request) (context.Context, context.CancelFunc) {
+	ctx := r.Context()
+	if h.config.slots != nil {
+		ctx = context.WithValue(ctx, renderSlotsKey{}, h.config.slots)
 	}
-
-	// Add any additional files from the request
-	for name, content := range files {
-		primary[name] = &fstest.MapFile{Data: []byte(content)}
+	if h.config.limits.Timeout > 0 {
+		return context.WithTimeout(ctx, h.config.limits.Timeout)
 	}
+	return context.WithCancel(ctx)
+}
 
-	if baseFS == nil {
-		return primary
+// renderSlotsKey is the context key of the render slots.
+type renderSlotsKey struct{}
+
+// decodeStatus maps a request body decoding error to a status code.
+func decodeStatus(err error) int {
+	if status := StatusCode(err); status != http.StatusInternalServerError {
+		return status
 	}
+	return http.StatusBadRequest
+}
 
-	return vuego.NewOverlayFS(primary, baseFS)
+func writeRenderResponse(w http.ResponseWriter, status int, resp RenderResponse) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
-// injectStyleLinksIntoTemplate injects style tags directly into the template string.
-// Assumes all files have already been processed (LESS compiled to CSS).
-func injectStyleLinksIntoTemplate(files map[string]string, template string) string {
-	// For API requests, look for style files in the files map
-	var styleFiles []string
-	for name := range files {
-		if strings.HasSuffix(name, ".css") {
-			styleFiles = append(styleFiles, name)
-		}
+// Render processes a RenderRequest and returns rendered HTML.
+// This function can be used by both HTTP handlers and CLI commands.
+// If the template specifies a layout in frontmatter, Layout() is used instead of Render().
+// Stylesheets from req.Files and sidecar assets of req.Name are inlined
+// into the result, see injectRequestAssets.
+// The result is converted to the format requested by req.Output.
+// Rendering stops with context.DeadlineExceeded when ctx expires; use StatusCode
+// to map the returned error to an HTTP status.
+func Render(ctx context.Context, baseFS fs.FS, req RenderRequest, opts ...vuego.LoadOption) (string, error) {
+	if err := validateFiles(req.Files, 0); err != nil {
+		return "", err
+	}
+	if err := validateOutput(req.Output); err != nil {
+		return "", err
 	}
 
-	if len(styleFiles) == 0 {
-		return template
+	data, err := parseData(req.Data)
+	if err != nil {
+		return "", err
 	}
+	return render(ctx, baseFS, req, data, opts...)
+}
 
-	// Build style tags
-	var styleTags strings.Builder
-	for _, name := range styleFiles {
-		if content, ok := files[name]; ok {
-			// All files at this point are CSS (LESS has been compiled)
-			styleTags.WriteString("\n<style>")
-			styleTags.WriteString(content)
-			styleTags.WriteString("</style>")
-		}
+// render renders req with data in place of req.Data.
+func render(ctx context.Context, baseFS fs.FS, req RenderRequest, data map[string]any, opts ...vuego.LoadOption) (string, error) {
+	// Build filesystem with template and any additional files
+	templateFS := buildTemplateFS(baseFS, req.Files, req.Template)
+
+	// Render the template
+	renderer := vuego.NewFS(templateFS, opts...)
+	html, err := renderTemplate(ctx, renderer.Load(templateName).Fill(data))
+	if err != nil {
+		return "", err
 	}
 
-	// Inject before </head> or </body>
-	if strings.Contains(template, "</head>") {
-		return strings.Replace(template, "</head>", styleTags.String()+"\n</head>", 1)
-	} else if strings.Contains(template, "</body>") {
-		return strings.Replace(template, "</body>", styleTags.String()+"\n</body>", 1)
+	html, err = injectRequestAssets(templateFS, req.Files, req.Name, req.Template, html)
+	if err != nil {
+		return "", unprocessable(err)
 	}
 
-	return template + styleTags.String()
+	output, err := formatOutput(html, req.Output)
+	if err != nil {
+		return "", unprocessable(err)
+	}
+	return output, nil
 }
 
-// injectStyleLinksForRequest injects style links for adjacent .less/.css files in the request files.
-func injectStyleLinksForRequest(buf *bytes.Buffer, files map[string]string) (string, error) {
-	// For API requests, look for style files in the files map
-	var styleFiles []string
-	for name := range files {
-		if strings.HasSuffix(name, ".less") || strings.HasSuffix(name, ".css") {
-			styleFiles = append(styleFiles, name)
+// parseData parses request data, which may be JSON or YAML.
+func parseData(source string) (map[string]any, error) {
+	var data map[string]any
+	if source != "" {
+		if err := yaml.Unmarshal([]byte(source), &data); err != nil {
+			return nil, badRequest(err)
 		}
 	}
+	if data == nil {
+		data = make(map[string]any)
+	}
+	return data, nil
+}
 
-	if len(styleFiles) == 0 {
-		return buf.String(), nil
+// renderTemplate renders tpl, returning ctx.Err() as soon as ctx expires.
+// The render runs in the background so an expired context returns promptly
+// even if the template itself does not observe cancellation. The render
+// holds one of the render slots of ctx, if any, until it finishes.
+func renderTemplate(ctx context.Context, tpl vuego.Template) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
-	// Inject style files as <style> tags or <link> tags
-	htmlContent := buf.String()
-	var styleTags strings.Builder
+	slots, _ := ctx.Value(renderSlotsKey{}).(chan struct{})
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
 
-	for _, name := range styleFiles {
-		if content, ok := files[name]; ok {
-			// For LESS files, use type="text/css+less" so the LessProcessor compiles it
-			if strings.HasSuffix(name, ".less") {
-				styleTags.WriteString("\n<style type=\"text/css+less\">")
-				styleTags.WriteString(content)
-				styleTags.WriteString("</style>")
-			} else {
-				// For plain CSS files, use regular style tag
-				styleTags.WriteString("\n<style>")
-				styleTags.WriteString(content)
-				styleTags.WriteString("</style>")
+	type result struct {
+		html string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if slots != nil {
+			defer func() { <-slots }()
+		}
+
+		var buf bytes.Buffer
+		err := tpl.Render(ctx, &buf)
+		done <- result{html: buf.String(), err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", ctxErr
 			}
+			return "", unprocessable(res.err)
 		}
+		return res.html, nil
+	}
+}
+
+// buildTemplateFS creates a filesystem combining the base FS with request files.
+func buildTemplateFS(baseFS fs.FS, files map[string]string, template string) fs.FS {
+	primary := fstest.MapFS{
+		templateName: &fstest.MapFile{Data: []byte(template)},
 	}
 
-	// Inject before </head> or </body>
-	if strings.Contains(htmlContent, "</head>") {
-		htmlContent = strings.Replace(htmlContent, "</head>", styleTags.String()+"\n</head>", 1)
-	} else if strings.Contains(htmlContent, "</body>") {
-		htmlContent = strings.Replace(htmlContent, "</body>", styleTags.String()+"\n</body>", 1)
-	} else {
-		htmlContent = htmlContent + styleTags.String()
+	// Add any additional files from the request
+	for name, content := range files {
+		primary[name] = &fstest.MapFile{Data: []byte(content)}
 	}
 
-	return htmlContent, nil
+	if baseFS == nil {
+		return primary
+	}
+
+	return vuego.NewOverlayFS(primary, baseFS)
+}
+
+// injectRequestAssets inlines the stylesheets of a request file set and the
+// sidecar assets of the named template into rendered HTML. Assets the
+// template source includes by name are left to the template.
+func injectRequestAssets(templateFS fs.FS, files map[string]string, name, source, html string) (string, error) {
+	assets := NewAssetInjector(templateFS, AssetInline)
+	assets.AddStylesheets(files)
+	if name != "" {
+		assets.AddSidecars(name, name)
+	}
+	assets.SkipReferenced(source)
+	return assets.Inject(html)
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -87,6 +88,8 @@ func TestRenderHandler_MethodNotAllowed(t *testing.T) {
 
 	handler.ServeHTTP(rec, req)
 
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
 	var resp server.RenderResponse
 	err := json.NewDecoder(rec.Body).Decode(&resp)
 	require.NoError(t, err)
@@ -102,12 +105,122 @@ func TestRenderHandler_InvalidJSON(t *testing.T) {
 
 	handler.ServeHTTP(rec, req)
 
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
 	var resp server.RenderResponse
 	err := json.NewDecoder(rec.Body).Decode(&resp)
 	require.NoError(t, err)
 	require.Contains(t, resp.Error, "invalid JSON:")
 }
 
+func postRender(t *testing.T, handler http.Handler, req server.RenderRequest) (*httptest.ResponseRecorder, server.RenderResponse) {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/render", bytes.NewReader(body)))
+
+	var resp server.RenderResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return rec, resp
+}
+
+func TestRenderHandler_StatusCodes(t *testing.T) {
+	handler := server.NewRenderHandler(nil)
+
+	t.Run("invalid data", func(t *testing.T) {
+		rec, resp := postRender(t, handler, server.RenderRequest{
+			Template: `<div>{{ name }}</div>`,
+			Data:     `{invalid`,
+		})
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.NotEmpty(t, resp.Error)
+	})
+
+	t.Run("template error", func(t *testing.T) {
+		rec, resp := postRender(t, handler, server.RenderRequest{
+			Template: `<template include="missing.vuego"></template>`,
+		})
+		require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		require.NotEmpty(t, resp.Error)
+	})
+}
+
+func TestRenderHandler_BodyTooLarge(t *testing.T) {
+	handler := server.NewRenderHandler(nil, server.WithLimits(server.Limits{MaxBodyBytes: 16}))
+
+	rec, resp := postRender(t, handler, server.RenderRequest{
+		Template: `<div>this body is larger than sixteen bytes</div>`,
+	})
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Contains(t, resp.Error, "invalid JSON:")
+}
+
+func TestRenderHandler_TooManyFiles(t *testing.T) {
+	handler := server.NewRenderHandler(nil, server.WithLimits(server.Limits{MaxFiles: 1}))
+
+	rec, resp := postRender(t, handler, server.RenderRequest{
+		Template: `<div></div>`,
+		Files: map[string]string{
+			"a.vuego": `<a></a>`,
+			"b.vuego": `<b></b>`,
+		},
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, resp.Error, "too many files")
+}
+
+func TestRenderHandler_InvalidFilePath(t *testing.T) {
+	handler := server.NewRenderHandler(nil)
+
+	for _, name := range []string{"../escape.vuego", "/abs.vuego", `dir\file.vuego`, "template.html", ""} {
+		rec, resp := postRender(t, handler, server.RenderRequest{
+			Template: `<div></div>`,
+			Files:    map[string]string{name: `<p></p>`},
+		})
+		require.Equal(t, http.StatusBadRequest, rec.Code, name)
+		require.Contains(t, resp.Error, "invalid file path", name)
+	}
+}
+
+func TestRenderHandler_Timeout(t *testing.T) {
+	handler := server.NewRenderHandler(nil, server.WithLimits(server.Limits{Timeout: time.Nanosecond}))
+
+	rec, resp := postRender(t, handler, server.RenderRequest{
+		Template: `<div>{{ name }}</div>`,
+	})
+	require.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	require.NotEmpty(t, resp.Error)
+}
+
+func TestRenderHandler_MaxRenders(t *testing.T) {
+	handler := server.NewRenderHandler(nil, server.WithLimits(server.Limits{Timeout: time.Second, MaxRenders: 1}))
+
+	// Every render releases its slot, so sequential requests never wait.
+	for i := 0; i < 3; i++ {
+		rec, resp := postRender(t, handler, server.RenderRequest{
+			Template: `<div>{{ name }}</div>`,
+			Data:     "name: World",
+		})
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, resp.HTML, "<div>World</div>")
+	}
+}
+
+func TestRenderHandler_RateLimit(t *testing.T) {
+	handler := server.NewRenderHandler(nil, server.WithRateLimit(0.001, 1))
+
+	rec, _ := postRender(t, handler, server.RenderRequest{Template: `<div></div>`})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec, resp := postRender(t, handler, server.RenderRequest{Template: `<div></div>`})
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "rate limit exceeded", resp.Error)
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+}
+
 func TestRender_NamedSlotDefault(t *testing.T) {
 	req := server.RenderRequest{
 		Template: `<template include="sidebar.vuego"></template>`,
@@ -175,3 +288,50 @@ layout: base.vuego
 	require.Contains(t, html, "<main>")
 	require.Contains(t, html, "</html>")
 }
+
+func TestRender_Output(t *testing.T) {
+	template := `<ul><li>{{ name }}</li></ul>`
+
+	html, err := server.Render(context.Background(), nil, server.RenderRequest{
+		Template: template,
+		Data:     "name: World",
+		Output:   server.OutputHTML,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "<ul><li>World</li></ul>\n", html)
+
+	formatted, err := server.Render(context.Background(), nil, server.RenderRequest{
+		Template: template,
+		Data:     "name: World",
+		Output:   server.OutputFormatted,
+	})
+	require.NoError(t, err)
+	require.Contains(t, formatted, "World")
+	require.Contains(t, formatted, "<li>")
+
+	normalized, err := server.Render(context.Background(), nil, server.RenderRequest{
+		Template: template,
+		Data:     "name: World",
+		Output:   server.OutputNormalized,
+	})
+	require.NoError(t, err)
+	require.Contains(t, normalized, "World")
+
+	dom, err := server.Render(context.Background(), nil, server.RenderRequest{
+		Template: template,
+		Data:     "name: World",
+		Output:   server.OutputYAML,
+	})
+	require.NoError(t, err)
+	require.Contains(t, dom, "ul")
+	require.Contains(t, dom, "World")
+}
+
+func TestRenderHandler_UnknownOutput(t *testing.T) {
+	rec, resp := postRender(t, server.NewRenderHandler(nil), server.RenderRequest{
+		Template: `<p></p>`,
+		Output:   "pdf",
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, resp.Error, `unknown output "pdf"`)
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "embed"
+)
+
+// RenderRequestV1 is the request body for POST /api/v1/render.
+// Data may be a JSON object or a string holding YAML (or JSON) source.
+// Name and Output behave like RenderRequest.Name and RenderRequest.Output.
+type RenderRequestV1 struct {
+	Name     string            `json:"name,omitempty"`
+	Template string            `json:"template"`
+	Data     json.RawMessage   `json:"data,omitempty"`
+	Files    map[string]string `json:"files,omitempty"`
+	Output   string            `json:"output,omitempty"`
+}
+
+// RenderResponseV1 is the response body for POST /api/v1/render.
+type RenderResponseV1 struct {
+	HTML       string       `json:"html,omitempty"`
+	Errors     []Diagnostic `json:"errors,omitempty"`
+	DurationMS float64      `json:"duration_ms"`
+}
+
+// Diagnostic describes a single problem with a render request.
+// Line and Column are 1-based and zero when unknown.
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Diagnostic file names for problems that are not tied to a request file.
+const (
+	diagnosticRequest  = "request"
+	diagnosticTemplate = "template"
+	diagnosticData     = "data"
+)
+
+// NewRenderV1Handler returns an http.Handler serving POST /api/v1/render.
+// It applies the same limits as NewRenderHandler and reports failures as
+// structured diagnostics alongside the render duration.
+func NewRenderV1Handler(baseFS fs.FS, opts ...RenderOption) http.Handler {
+	return &renderV1Handler{
+		renderHandler: newRenderHandler(baseFS, opts...),
+	}
+}
+
+type renderV1Handler struct {
+	*renderHandler
+}
+
+func (h *renderV1Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	w.Header().Set("Content-Type", "application/json")
+
+	fail := func(status int, diag Diagnostic) {
+		writeRenderV1Response(w, status, start, RenderResponseV1{
+			Errors: []Diagnostic{diag},
+		})
+	}
+
+	if err := h.admit(w, r); err != nil {
+		fail(StatusCode(err), Diagnostic{File: diagnosticRequest, Message: err.Error()})
+		return
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		fail(decodeStatus(err), Diagnostic{File: diagnosticRequest, Message: err.Error()})
+		return
+	}
+
+	var req RenderRequestV1
+	if err := json.Unmarshal(body, &req); err != nil {
+		fail(http.StatusBadRequest, jsonDiagnostic(body, err))
+		return
+	}
+
+	data, err := req.data()
+	if err != nil {
+		fail(http.StatusBadRequest, Diagnose(err, diagnosticData))
+		return
+	}
+
+	v0 := RenderRequest{
+		Name:     req.Name,
+		Template: req.Template,
+		Files:    req.Files,
+		Output:   req.Output,
+	}
+	if err := validateFiles(v0.Files, h.config.limits.MaxFiles); err != nil {
+		fail(StatusCode(err), Diagnostic{File: diagnosticRequest, Message: err.Error()})
+		return
+	}
+	if err := validateOutput(v0.Output); err != nil {
+		fail(StatusCode(err), Diagnostic{File: diagnosticRequest, Message: err.Error()})
+		return
+	}
+
+	ctx, cancel := h.context(r)
+	defer cancel()
+
+	html, err := render(ctx, h.fs, v0, data, h.config.loadOptions...)
+	if err != nil {
+		status := StatusCode(err)
+		file := diagnosticTemplate
+		if status == http.StatusBadRequest {
+			file = diagnosticData
+		}
+		fail(status, Diagnose(err, file))
+		return
+	}
+
+	writeRenderV1Response(w, http.StatusOK, start, RenderResponseV1{
+		HTML: html,
+	})
+}
+
+// data returns the request data. JSON objects are decoded as JSON, strings
+// are parsed as YAML source like RenderRequest.Data.
+func (req RenderRequestV1) data() (map[string]any, error) {
+	raw := bytes.TrimSpace(req.Data)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return make(map[string]any), nil
+	}
+	switch raw[0] {
+	case '{':
+		var data map[string]any
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	case '"':
+		var source string
+		if err := json.Unmarshal(raw, &source); err != nil {
+			return nil, err
+		}
+		return parseData(source)
+	}
+	return nil, errors.New("data must be a JSON object or a YAML string")
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r.Body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeRenderV1Response(w http.ResponseWriter, status int, start time.Time, resp RenderResponseV1) {
+	elapsed := time.Since(start)
+	resp.DurationMS = float64(elapsed.Microseconds()) / 1000
+	w.Header().Set("Server-Timing", fmt.Sprintf("render;dur=%.3f", resp.DurationMS))
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// jsonDiagnostic reports a JSON decoding error with the position in body.
+func jsonDiagnostic(body []byte, err error) Diagnostic {
+	diag := Diagnostic{File: diagnosticRequest, Message: "invalid JSON: " + err.Error()}
+
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	if offset >= 0 && offset <= int64(len(body)) {
+		before := body[:offset]
+		diag.Line = bytes.Count(before, []byte("\n")) + 1
+		diag.Column = int(offset) - (bytes.LastIndexByte(before, '\n') + 1)
+		if diag.Column < 1 {
+			diag.Column = 1
+		}
+	}
+	return diag
+}
+
+var (
+	// diagnosticFilePos matches "name.ext:line[:column]" positions.
+	diagnosticFilePos = regexp.MustCompile(`([\w./-]+\.(?:vuego|html|less|css|ya?ml|json|md)):(\d+)(?::(\d+))?`)
+	// diagnosticLinePos matches "line N[, column M]" positions.
+	diagnosticLinePos = regexp.MustCompile(`(?i)\bline (\d+)(?:,? (?:column|col) (\d+))?`)
+)
+
+// Diagnose converts an error into a Diagnostic, extracting the file, line
+// and column when the error message carries them. The file defaults to
+// defaultFile; the request template is reported as "template".
+func Diagnose(err error, defaultFile string) Diagnostic {
+	msg := err.Error()
+	diag := Diagnostic{File: defaultFile, Message: msg}
+
+	if m := diagnosticFilePos.FindStringSubmatch(msg); m != nil {
+		diag.File = m[1]
+		diag.Line, _ = strconv.Atoi(m[2])
+		diag.Column, _ = strconv.Atoi(m[3])
+	} else if m := diagnosticLinePos.FindStringSubmatch(msg); m != nil {
+		diag.Line, _ = strconv.Atoi(m[1])
+		diag.Column, _ = strconv.Atoi(m[2])
+	}
+
+	if diag.File == templateName || strings.HasSuffix(diag.File, "/"+templateName) {
+		diag.File = diagnosticTemplate
+	}
+	return diag
+}
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// OpenAPIHandler serves the OpenAPI document describing the v1 render API.
+func OpenAPIHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
@@ -0,0 +1,101 @@
+package server_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/vuego-cli/server"
+)
+
+func postRenderV1(t *testing.T, body string) (*httptest.ResponseRecorder, server.RenderResponseV1) {
+	t.Helper()
+
+	handler := server.NewRenderV1Handler(nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/render", strings.NewReader(body)))
+
+	var resp server.RenderResponseV1
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return rec, resp
+}
+
+func TestRenderV1_ObjectData(t *testing.T) {
+	rec, resp := postRenderV1(t, `{"template": "<p>{{ user.name }}</p>", "data": {"user": {"name": "Ada"}}}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, resp.Errors)
+	require.Equal(t, "<p>Ada</p>\n", resp.HTML)
+	require.Contains(t, rec.Header().Get("Server-Timing"), "render;dur=")
+}
+
+func TestRenderV1_YAMLStringData(t *testing.T) {
+	rec, resp := postRenderV1(t, `{"template": "<p>{{ name }}</p>", "data": "name: Ada"}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "<p>Ada</p>\n", resp.HTML)
+}
+
+func TestRenderV1_JSONDuplicateKeys(t *testing.T) {
+	rec, resp := postRenderV1(t, `{"template": "<p>{{ name }}</p>", "data": {"name": "Bob", "name": "Ada"}}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "<p>Ada</p>\n", resp.HTML)
+}
+
+func TestRenderV1_InvalidDataType(t *testing.T) {
+	rec, resp := postRenderV1(t, `{"template": "<p></p>", "data": [1, 2]}`)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "data", resp.Errors[0].File)
+}
+
+func TestRenderV1_InvalidJSONPosition(t *testing.T) {
+	rec, resp := postRenderV1(t, "{\n  \"template\": \"<p></p>\",\n  oops\n}")
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "request", resp.Errors[0].File)
+	require.Equal(t, 3, resp.Errors[0].Line)
+	require.Positive(t, resp.Errors[0].Column)
+}
+
+func TestRenderV1_YAMLErrorLine(t *testing.T) {
+	rec, resp := postRenderV1(t, `{"template": "<p></p>", "data": "name: ok\nbad: [unclosed"}`)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Len(t, resp.Errors, 1)
+	require.Equal(t, "data", resp.Errors[0].File)
+	require.Positive(t, resp.Errors[0].Line)
+}
+
+func TestDiagnose(t *testing.T) {
+	diag := server.Diagnose(errors.New("partials/card.vuego:12:4: unexpected token"), "template")
+	require.Equal(t, server.Diagnostic{File: "partials/card.vuego", Line: 12, Column: 4, Message: "partials/card.vuego:12:4: unexpected token"}, diag)
+
+	diag = server.Diagnose(errors.New("template.html:3: bad expression"), "template")
+	require.Equal(t, "template", diag.File)
+	require.Equal(t, 3, diag.Line)
+
+	diag = server.Diagnose(errors.New("yaml: line 2: did not find expected node content"), "data")
+	require.Equal(t, "data", diag.File)
+	require.Equal(t, 2, diag.Line)
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	server.OpenAPIHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+	require.Equal(t, "3.0.3", doc["openapi"])
+	require.Contains(t, doc["paths"], "/api/v1/render")
+}
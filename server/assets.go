@@ -0,0 +1,214 @@
+package server
+
+import (
+	"fmt"
+	"html"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/titpetric/lessgo/dst"
+	"github.com/titpetric/lessgo/renderer"
+)
+
+// AssetMode selects how assets are injected into rendered HTML.
+type AssetMode int
+
+const (
+	// AssetLink references assets with <link> and <script src> tags.
+	AssetLink AssetMode = iota
+	// AssetInline embeds asset contents in <style> and <script> tags,
+	// compiling LESS to CSS.
+	AssetInline
+)
+
+// Asset is a stylesheet (.css, .less) or script (.js) injected into HTML.
+type Asset struct {
+	// Path is the asset location within the injector filesystem.
+	Path string
+	// URL is the address the asset is referenced by from the page.
+	URL string
+}
+
+func (a Asset) isScript() bool {
+	return path.Ext(a.Path) == ".js"
+}
+
+// AssetInjector collects the stylesheets and scripts of a page and injects
+// them into its rendered HTML.
+//
+// Assets are injected in the order they were added and each path is only
+// injected once. Assets the page already references by URL are not added
+// again; in inline mode an existing <link rel="stylesheet"> or <script src>
+// tag for the asset is replaced by the asset contents instead.
+type AssetInjector struct {
+	fs     fs.FS
+	mode   AssetMode
+	assets []Asset
+	seen   map[string]bool
+	skip   map[string]bool
+}
+
+// NewAssetInjector creates an AssetInjector reading assets from fsys.
+func NewAssetInjector(fsys fs.FS, mode AssetMode) *AssetInjector {
+	return &AssetInjector{
+		fs:   fsys,
+		mode: mode,
+		seen: make(map[string]bool),
+		skip: make(map[string]bool),
+	}
+}
+
+// Add adds assets, ignoring paths that were already added.
+func (a *AssetInjector) Add(assets ...Asset) {
+	for _, asset := range assets {
+		if a.seen[asset.Path] {
+			continue
+		}
+		a.seen[asset.Path] = true
+		a.assets = append(a.assets, asset)
+	}
+}
+
+// AddSidecars adds the assets adjacent to a template: a stylesheet with the
+// same base name (.css preferred over .less) and a .js script. The urlPath
+// is the template URL the sidecar URLs are derived from, with or without
+// the .vuego extension.
+func (a *AssetInjector) AddSidecars(filePath, urlPath string) {
+	basePath := strings.TrimSuffix(filePath, path.Ext(filePath))
+	urlBasePath := strings.TrimSuffix(urlPath, ".vuego")
+
+	for _, exts := range [][]string{{".css", ".less"}, {".js"}} {
+		for _, ext := range exts {
+			if _, err := fs.Stat(a.fs, basePath+ext); err == nil {
+				a.Add(Asset{Path: basePath + ext, URL: urlBasePath + ext})
+				break
+			}
+		}
+	}
+}
+
+// AddStylesheets adds all .css and .less files of a request file set,
+// sorted by name. The file names are used as URLs.
+func (a *AssetInjector) AddStylesheets(files map[string]string) {
+	var names []string
+	for name := range files {
+		switch path.Ext(name) {
+		case ".css", ".less":
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a.Add(Asset{Path: name, URL: name})
+	}
+}
+
+// SkipReferenced excludes assets the template source refers to by name,
+// for example through file("page.less"), since the template includes them
+// itself. Link and script tags for them are still inlined in inline mode.
+func (a *AssetInjector) SkipReferenced(source string) {
+	for _, asset := range a.assets {
+		for _, name := range []string{asset.URL, asset.Path} {
+			if strings.Contains(source, `"`+name+`"`) || strings.Contains(source, `'`+name+`'`) {
+				a.skip[asset.Path] = true
+			}
+		}
+	}
+}
+
+// Inject injects the collected assets into htmlContent. Stylesheets are
+// placed before </head>, scripts before </body>; either falls back to the
+// end of the document.
+func (a *AssetInjector) Inject(htmlContent string) (string, error) {
+	var styles, scripts strings.Builder
+
+	for _, asset := range a.assets {
+		loc := findAssetReference(htmlContent, asset)
+		if loc == nil && a.skip[asset.Path] || loc != nil && a.mode == AssetLink {
+			continue
+		}
+
+		tag, err := a.tag(asset)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case loc != nil:
+			htmlContent = htmlContent[:loc[0]] + tag + htmlContent[loc[1]:]
+		case asset.isScript():
+			scripts.WriteString(tag)
+		default:
+			styles.WriteString(tag)
+		}
+	}
+
+	htmlContent = insertBefore(htmlContent, styles.String(), "</head>", "</body>")
+	htmlContent = insertBefore(htmlContent, scripts.String(), "</body>")
+	return htmlContent, nil
+}
+
+// tag returns the HTML tag injecting asset in the injector mode.
+func (a *AssetInjector) tag(asset Asset) (string, error) {
+	if a.mode == AssetLink {
+		if asset.isScript() {
+			return fmt.Sprintf(`<script src="%s"></script>`, html.EscapeString(asset.URL)), nil
+		}
+		return fmt.Sprintf(`<link rel="stylesheet" href="%s">`, html.EscapeString(asset.URL)), nil
+	}
+
+	content, err := fs.ReadFile(a.fs, asset.Path)
+	if err != nil {
+		return "", fmt.Errorf("reading asset %s: %w", asset.Path, err)
+	}
+
+	switch path.Ext(asset.Path) {
+	case ".js":
+		return "<script>" + string(content) + "</script>", nil
+	case ".less":
+		css, err := compileLess(a.fs, string(content))
+		if err != nil {
+			return "", fmt.Errorf("compiling %s: %w", asset.Path, err)
+		}
+		return "<style>" + css + "</style>", nil
+	}
+	return "<style>" + string(content) + "</style>", nil
+}
+
+// compileLess compiles LESS source to CSS, resolving imports from fsys.
+func compileLess(fsys fs.FS, source string) (string, error) {
+	file, err := dst.NewParserWithFS(strings.NewReader(source), fsys).Parse()
+	if err != nil {
+		return "", err
+	}
+	return renderer.NewRenderer().Render(file)
+}
+
+// findAssetReference returns the location of an existing <link> or
+// <script src> tag referencing the asset URL, or nil.
+func findAssetReference(htmlContent string, asset Asset) []int {
+	url := regexp.QuoteMeta(asset.URL)
+	pattern := `<link\b[^>]*\bhref=["']` + url + `["'][^>]*>`
+	if asset.isScript() {
+		pattern = `<script\b[^>]*\bsrc=["']` + url + `["'][^>]*>\s*</script>`
+	}
+	return regexp.MustCompile(pattern).FindStringIndex(htmlContent)
+}
+
+// insertBefore inserts snippet before the first of the given closing tags
+// found in htmlContent, or appends it when none is present.
+func insertBefore(htmlContent, snippet string, closingTags ...string) string {
+	if snippet == "" {
+		return htmlContent
+	}
+	for _, closingTag := range closingTags {
+		if idx := strings.Index(htmlContent, closingTag); idx >= 0 {
+			return htmlContent[:idx] + snippet + htmlContent[idx:]
+		}
+	}
+	return htmlContent + snippet
+}
@@ -0,0 +1,107 @@
+package server_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/vuego-cli/server"
+)
+
+func TestAssetInjector_LinkSidecars(t *testing.T) {
+	fs := fstest.MapFS{
+		"page.vuego": &fstest.MapFile{Data: []byte(`<div></div>`)},
+		"page.css":   &fstest.MapFile{Data: []byte(`div { color: red; }`)},
+		"page.less":  &fstest.MapFile{Data: []byte(`div { color: blue; }`)},
+		"page.js":    &fstest.MapFile{Data: []byte(`console.log("page")`)},
+	}
+
+	assets := server.NewAssetInjector(fs, server.AssetLink)
+	assets.AddSidecars("page.vuego", "/page")
+
+	html, err := assets.Inject(`<html><head></head><body><div></div></body></html>`)
+	require.NoError(t, err)
+	require.Equal(t, `<html><head><link rel="stylesheet" href="/page.css"></head><body><div></div><script src="/page.js"></script></body></html>`, html)
+}
+
+func TestAssetInjector_LinkSidecarsDottedURL(t *testing.T) {
+	fs := fstest.MapFS{
+		"release-1.2.vuego": &fstest.MapFile{Data: []byte(`<div></div>`)},
+		"release-1.2.css":   &fstest.MapFile{Data: []byte(`div { color: red; }`)},
+	}
+
+	assets := server.NewAssetInjector(fs, server.AssetLink)
+	assets.AddSidecars("release-1.2.vuego", "/release-1.2")
+
+	html, err := assets.Inject(`<head></head>`)
+	require.NoError(t, err)
+	require.Equal(t, `<head><link rel="stylesheet" href="/release-1.2.css"></head>`, html)
+}
+
+func TestAssetInjector_LinkSkipsExistingReference(t *testing.T) {
+	fs := fstest.MapFS{
+		"page.css": &fstest.MapFile{Data: []byte(`div { color: red; }`)},
+	}
+
+	assets := server.NewAssetInjector(fs, server.AssetLink)
+	assets.AddSidecars("page.vuego", "/page.vuego")
+
+	input := `<head><link rel="stylesheet" href="/page.css"></head>`
+	html, err := assets.Inject(input)
+	require.NoError(t, err)
+	require.Equal(t, input, html)
+}
+
+func TestAssetInjector_InlineCompilesLess(t *testing.T) {
+	fs := fstest.MapFS{
+		"b.less": &fstest.MapFile{Data: []byte("@c: #fff;\n.b {\n  a {\n    color: @c;\n  }\n}\n")},
+		"a.css":  &fstest.MapFile{Data: []byte(".a { color: red; }")},
+	}
+
+	assets := server.NewAssetInjector(fs, server.AssetInline)
+	assets.AddStylesheets(map[string]string{"b.less": "", "a.css": "", "data.yml": ""})
+	assets.Add(server.Asset{Path: "a.css", URL: "a.css"})
+
+	html, err := assets.Inject(`<div></div>`)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(html, "<style>"))
+	require.Less(t, strings.Index(html, ".a {"), strings.Index(html, ".b a {"))
+	require.Contains(t, html, "#fff")
+}
+
+func TestAssetInjector_InlineReplacesLinkTag(t *testing.T) {
+	fs := fstest.MapFS{
+		"form.less": &fstest.MapFile{Data: []byte(`.form { b { x: y; } }`)},
+		"form.js":   &fstest.MapFile{Data: []byte(`init()`)},
+	}
+
+	source := `<head><link rel="stylesheet" href="form.less"></head><body><script src="form.js"></script></body>`
+
+	assets := server.NewAssetInjector(fs, server.AssetInline)
+	assets.AddSidecars("form.vuego", "form.vuego")
+	assets.SkipReferenced(source)
+
+	html, err := assets.Inject(source)
+	require.NoError(t, err)
+	require.NotContains(t, html, "<link")
+	require.NotContains(t, html, `src="form.js"`)
+	require.Contains(t, html, "<head><style>")
+	require.Contains(t, html, "<script>init()</script></body>")
+}
+
+func TestAssetInjector_SkipReferenced(t *testing.T) {
+	fs := fstest.MapFS{
+		"card.less": &fstest.MapFile{Data: []byte(`.card { x: y; }`)},
+		"card.js":   &fstest.MapFile{Data: []byte(`init()`)},
+	}
+
+	assets := server.NewAssetInjector(fs, server.AssetInline)
+	assets.AddSidecars("card.vuego", "card.vuego")
+	assets.SkipReferenced(`<style type="text/css+less">{{ file("card.less") }}</style>`)
+
+	html, err := assets.Inject(`<body></body>`)
+	require.NoError(t, err)
+	require.Equal(t, `<body><script>init()</script></body>`, html)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing/fstest"
+
+	"github.com/titpetric/vuego"
+)
+
+// BatchRequest renders several templates against one shared set of files.
+type BatchRequest struct {
+	Files     map[string]string `json:"files,omitempty"`
+	Templates []BatchTemplate   `json:"templates"`
+}
+
+// BatchTemplate is a single template and its data within a BatchRequest.
+// Name and Output behave like RenderRequest.Name and RenderRequest.Output.
+type BatchTemplate struct {
+	Name     string `json:"name,omitempty"`
+	Template string `json:"template"`
+	Data     string `json:"data"`
+	Output   string `json:"output,omitempty"`
+}
+
+// BatchResponse contains one result per template, in request order.
+type BatchResponse struct {
+	Results []RenderResponse `json:"results,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// RenderBatch renders all templates of a BatchRequest concurrently and
+// returns the results in request order. Failures are reported per item.
+//
+// All templates share one filesystem that reads every file of baseFS at
+// most once per batch. Items are rendered by a pool of workers, each with
+// a renderer of its own that it reuses for the items it renders, so a
+// renderer is never used concurrently. Parsed templates are not shared
+// between workers: vuego has no concurrency-safe parse cache to share.
+func RenderBatch(ctx context.Context, baseFS fs.FS, req BatchRequest, opts ...vuego.LoadOption) ([]RenderResponse, error) {
+	if err := validateFiles(req.Files, 0); err != nil {
+		return nil, err
+	}
+
+	primary := fstest.MapFS{}
+	for name, content := range req.Files {
+		primary[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	for i, item := range req.Templates {
+		primary[batchTemplateName(i)] = &fstest.MapFile{Data: []byte(item.Template)}
+	}
+
+	var templateFS fs.FS = primary
+	if baseFS != nil {
+		templateFS = vuego.NewOverlayFS(primary, newCacheFS(baseFS))
+	}
+	results := make([]RenderResponse, len(req.Templates))
+	items := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(req.Templates)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			renderer := vuego.NewFS(templateFS, opts...)
+			for i := range items {
+				results[i] = renderBatchItem(ctx, renderer, templateFS, req.Files, i, req.Templates[i])
+			}
+		}()
+	}
+	for i := range req.Templates {
+		items <- i
+	}
+	close(items)
+	wg.Wait()
+
+	return results, nil
+}
+
+func renderBatchItem(ctx context.Context, renderer vuego.Template, templateFS fs.FS, files map[string]string, i int, item BatchTemplate) RenderResponse {
+	if err := validateOutput(item.Output); err != nil {
+		return RenderResponse{Error: err.Error()}
+	}
+
+	data, err := parseData(item.Data)
+	if err != nil {
+		return RenderResponse{Error: err.Error()}
+	}
+
+	html, err := renderTemplate(ctx, renderer.Load(batchTemplateName(i)).Fill(data))
+	if err != nil {
+		return RenderResponse{Error: err.Error()}
+	}
+
+	html, err = injectRequestAssets(templateFS, files, item.Name, item.Template, html)
+	if err != nil {
+		return RenderResponse{Error: err.Error()}
+	}
+
+	output, err := formatOutput(html, item.Output)
+	if err != nil {
+		return RenderResponse{Error: err.Error()}
+	}
+	return RenderResponse{HTML: output}
+}
+
+func batchTemplateName(i int) string {
+	return fmt.Sprintf("template-%d.html", i)
+}
+
+// NewBatchHandler returns an http.Handler that renders a BatchRequest via POST.
+// Limits apply to the batch as a whole; item failures are reported in the
+// results while the response itself succeeds.
+func NewBatchHandler(baseFS fs.FS, opts ...RenderOption) http.Handler {
+	return &batchHandler{
+		renderHandler: newRenderHandler(baseFS, opts...),
+	}
+}
+
+type batchHandler struct {
+	*renderHandler
+}
+
+func (h *batchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := h.admit(w, r); err != nil {
+		writeBatchResponse(w, StatusCode(err), BatchResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeBatchResponse(w, decodeStatus(err), BatchResponse{
+			Error: "invalid JSON: " + err.Error(),
+		})
+		return
+	}
+
+	limits := h.config.limits
+	if limits.MaxBatch > 0 && len(req.Templates) > limits.MaxBatch {
+		err := badRequest(fmt.Errorf("%w: %d (max %d)", ErrTooManyTemplates, len(req.Templates), limits.MaxBatch))
+		writeBatchResponse(w, StatusCode(err), BatchResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	if err := validateFiles(req.Files, limits.MaxFiles); err != nil {
+		writeBatchResponse(w, StatusCode(err), BatchResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := h.context(r)
+	defer cancel()
+
+	results, err := RenderBatch(ctx, h.fs, req, h.config.loadOptions...)
+	if err != nil {
+		writeBatchResponse(w, StatusCode(err), BatchResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	writeBatchResponse(w, http.StatusOK, BatchResponse{
+		Results: results,
+	})
+}
+
+func writeBatchResponse(w http.ResponseWriter, status int, resp BatchResponse) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
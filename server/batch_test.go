@@ -0,0 +1,115 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/titpetric/vuego-cli/server"
+)
+
+func TestRenderBatch_OrderAndErrors(t *testing.T) {
+	baseFS := fstest.MapFS{
+		"card.vuego": &fstest.MapFile{Data: []byte(`<div class="card">{{ title }}</div>`)},
+	}
+
+	req := server.BatchRequest{
+		Files: map[string]string{
+			"badge.vuego": `<span>{{ label }}</span>`,
+		},
+	}
+	for i := 0; i < 20; i++ {
+		req.Templates = append(req.Templates, server.BatchTemplate{
+			Template: `<template include="card.vuego"></template>`,
+			Data:     fmt.Sprintf("title: Card %d", i),
+		})
+	}
+	req.Templates = append(req.Templates,
+		server.BatchTemplate{Template: `<template include="badge.vuego"></template>`, Data: "label: New"},
+		server.BatchTemplate{Template: `<p></p>`, Data: "{invalid"},
+	)
+
+	results, err := server.RenderBatch(context.Background(), baseFS, req)
+	require.NoError(t, err)
+	require.Len(t, results, 22)
+
+	for i := 0; i < 20; i++ {
+		require.Empty(t, results[i].Error)
+		require.Contains(t, results[i].HTML, fmt.Sprintf("Card %d<", i))
+	}
+	require.Contains(t, results[20].HTML, "<span>New</span>")
+	require.NotEmpty(t, results[21].Error)
+	require.Empty(t, results[21].HTML)
+}
+
+// TestRenderBatch_Concurrent renders items that share included files in
+// parallel. Run it with -race to check the shared filesystem.
+func TestRenderBatch_Concurrent(t *testing.T) {
+	baseFS := fstest.MapFS{
+		"layout.vuego": &fstest.MapFile{Data: []byte(`<main><template include="card.vuego"></template></main>`)},
+		"card.vuego":   &fstest.MapFile{Data: []byte(`<div class="card">{{ title }}</div>`)},
+	}
+
+	req := server.BatchRequest{}
+	for i := 0; i < 64; i++ {
+		req.Templates = append(req.Templates, server.BatchTemplate{
+			Template: `<template include="layout.vuego"></template>`,
+			Data:     fmt.Sprintf("title: Card %d", i),
+		})
+	}
+
+	results, err := server.RenderBatch(context.Background(), baseFS, req)
+	require.NoError(t, err)
+	for i, result := range results {
+		require.Empty(t, result.Error)
+		require.Contains(t, result.HTML, fmt.Sprintf(`<div class="card">Card %d</div>`, i))
+	}
+}
+
+func TestRenderBatch_ReservedFileName(t *testing.T) {
+	_, err := server.RenderBatch(context.Background(), nil, server.BatchRequest{
+		Files:     map[string]string{"template-0.html": `<p></p>`},
+		Templates: []server.BatchTemplate{{Template: `<div></div>`}},
+	})
+	require.ErrorIs(t, err, server.ErrInvalidPath)
+}
+
+func TestBatchHandler(t *testing.T) {
+	handler := server.NewBatchHandler(nil, server.WithLimits(server.Limits{MaxBatch: 2}))
+
+	post := func(req server.BatchRequest) (*httptest.ResponseRecorder, server.BatchResponse) {
+		body, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/render/batch", bytes.NewReader(body)))
+
+		var resp server.BatchResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		return rec, resp
+	}
+
+	rec, resp := post(server.BatchRequest{
+		Templates: []server.BatchTemplate{
+			{Template: `<b>{{ n }}</b>`, Data: "n: 1"},
+			{Template: `<b>{{ n }}</b>`, Data: "n: 2"},
+		},
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, resp.Results, 2)
+	require.Equal(t, "<b>1</b>\n", resp.Results[0].HTML)
+	require.Equal(t, "<b>2</b>\n", resp.Results[1].HTML)
+
+	rec, resp = post(server.BatchRequest{
+		Templates: make([]server.BatchTemplate, 3),
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, resp.Error, "too many templates")
+}
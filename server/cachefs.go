@@ -0,0 +1,70 @@
+package server
+
+import (
+	"io/fs"
+	"sync"
+	"testing/fstest"
+)
+
+// cacheFS memoizes regular file contents of an underlying filesystem,
+// so files shared by the items of a batch are read only once. It caches
+// file bytes, not parsed templates, and lives for a single batch.
+// Directories are passed through uncached.
+type cacheFS struct {
+	fs    fs.FS
+	files sync.Map // name -> *fstest.MapFile
+}
+
+func newCacheFS(fsys fs.FS) *cacheFS {
+	return &cacheFS{fs: fsys}
+}
+
+func (c *cacheFS) Open(name string) (fs.File, error) {
+	file, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return c.fs.Open(name)
+	}
+	return fstest.MapFS{name: file}.Open(name)
+}
+
+func (c *cacheFS) ReadFile(name string) ([]byte, error) {
+	file, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return fs.ReadFile(c.fs, name)
+	}
+	return append([]byte(nil), file.Data...), nil
+}
+
+// load returns the cached file for name, or nil if name is a directory.
+func (c *cacheFS) load(name string) (*fstest.MapFile, error) {
+	if cached, ok := c.files.Load(name); ok {
+		return cached.(*fstest.MapFile), nil
+	}
+
+	info, err := fs.Stat(c.fs, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+
+	data, err := fs.ReadFile(c.fs, name)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &fstest.MapFile{
+		Data:    data,
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+	cached, _ := c.files.LoadOrStore(name, file)
+	return cached.(*fstest.MapFile), nil
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Limits bounds the resources a single render request may use.
+// A zero value for any field disables that limit.
+type Limits struct {
+	// MaxBodyBytes is the maximum size of the request body.
+	MaxBodyBytes int64
+	// MaxFiles is the maximum number of entries in RenderRequest.Files.
+	MaxFiles int
+	// MaxBatch is the maximum number of templates in a BatchRequest.
+	MaxBatch int
+	// Timeout is the maximum time a render may take. A request is answered
+	// when it expires, but the render itself runs on until it finishes;
+	// MaxRenders bounds how many of those run at once.
+	Timeout time.Duration
+	// MaxRenders is the maximum number of renders running at once across
+	// all requests of a handler. A render keeps its slot until it finishes,
+	// even after its request timed out. Requests wait for a free slot
+	// within their Timeout.
+	MaxRenders int
+}
+
+// DefaultLimits are the limits used by RenderHandler when none are given.
+var DefaultLimits = Limits{
+	MaxBodyBytes: 1 << 20,
+	MaxFiles:     64,
+	MaxBatch:     64,
+	Timeout:      5 * time.Second,
+	MaxRenders:   64,
+}
+
+var (
+	// ErrTooManyFiles is returned when a request carries more files than allowed.
+	ErrTooManyFiles = errors.New("too many files")
+	// ErrInvalidPath is returned when a request file name is not a valid relative path.
+	ErrInvalidPath = errors.New("invalid file path")
+	// ErrTooManyTemplates is returned when a batch carries more templates than allowed.
+	ErrTooManyTemplates = errors.New("too many templates")
+)
+
+// templateName is the file name the request template is mounted as.
+const templateName = "template.html"
+
+// reservedName matches the names request templates are mounted as,
+// including the numbered templates of a batch.
+var reservedName = regexp.MustCompile(`^template(-\d+)?\.html$`)
+
+// validateFiles checks the request files against the limits and rejects
+// names that could escape or shadow the request filesystem.
+func validateFiles(files map[string]string, maxFiles int) error {
+	if maxFiles > 0 && len(files) > maxFiles {
+		return badRequest(fmt.Errorf("%w: %d (max %d)", ErrTooManyFiles, len(files), maxFiles))
+	}
+	for name := range files {
+		if err := validatePath(name); err != nil {
+			return badRequest(err)
+		}
+	}
+	return nil
+}
+
+func validatePath(name string) error {
+	switch {
+	case reservedName.MatchString(name):
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidPath, name)
+	case strings.Contains(name, `\`), !fs.ValidPath(name), name == ".":
+		return fmt.Errorf("%w: %q", ErrInvalidPath, name)
+	}
+	return nil
+}
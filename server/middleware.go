@@ -81,8 +81,10 @@ func (h *middlewareHandler) serveVuego(w http.ResponseWriter, r *http.Request, f
 		return
 	}
 
-	// Inject style link for adjacent .css or .less file
-	html, err := injectStyleLink(&buf, h.fs, filePath, r.URL.Path)
+	// Link adjacent .css/.less and .js files
+	assets := NewAssetInjector(h.fs, AssetLink)
+	assets.AddSidecars(filePath, r.URL.Path)
+	html, err := assets.Inject(buf.String())
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to process HTML: %v", err), http.StatusInternalServerError)
 		return
@@ -123,37 +125,3 @@ func MiddlewareDir(dir string, opts ...MiddlewareOption) http.Handler {
 	}
 	return Middleware(os.DirFS(absDir), opts...)
 }
-
-// injectStyleLink finds an adjacent .css or .less file (preferring .css) and injects a style link.
-func injectStyleLink(buf *bytes.Buffer, contentFS fs.FS, vuegoPath, urlPath string) (string, error) {
-	htmlContent := buf.String()
-	basePath := strings.TrimSuffix(vuegoPath, ".vuego")
-	urlBasePath := strings.TrimSuffix(urlPath, ".vuego")
-
-	// Check for .css first (preferred), then .less
-	var styleExt string
-	for _, ext := range []string{".css", ".less"} {
-		stylePath := basePath + ext
-		if _, err := fs.Stat(contentFS, stylePath); err == nil {
-			styleExt = ext
-			break
-		}
-	}
-
-	// If no style file found, return original content
-	if styleExt == "" {
-		return htmlContent, nil
-	}
-
-	// Build single style link tag
-	styleLink := fmt.Sprintf(`<link rel="stylesheet" href="%s%s">`, urlBasePath, styleExt)
-
-	// Inject before </head> if it exists, otherwise before </body>, or at the end
-	if idx := strings.Index(htmlContent, "</head>"); idx >= 0 {
-		return htmlContent[:idx] + styleLink + htmlContent[idx:], nil
-	}
-	if idx := strings.Index(htmlContent, "</body>"); idx >= 0 {
-		return htmlContent[:idx] + styleLink + htmlContent[idx:], nil
-	}
-	return htmlContent + styleLink, nil
-}
@@ -60,6 +60,24 @@ func TestMiddleware(t *testing.T) {
 		require.Contains(t, rec.Body.String(), "No Extension")
 	})
 
+	t.Run("links adjacent stylesheet and script", func(t *testing.T) {
+		fs := fstest.MapFS{
+			"page.vuego": &fstest.MapFile{Data: []byte(`<html><head></head><body><div>Styled</div></body></html>`)},
+			"page.less":  &fstest.MapFile{Data: []byte(`div { color: red; }`)},
+			"page.js":    &fstest.MapFile{Data: []byte(`console.log("page")`)},
+		}
+
+		handler := server.Middleware(fs)
+		req := httptest.NewRequest(http.MethodGet, "/page", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), `<link rel="stylesheet" href="/page.less">`)
+		require.Contains(t, rec.Body.String(), `<script src="/page.js"></script>`)
+	})
+
 	t.Run("returns 404 for non-.vuego files", func(t *testing.T) {
 		fs := fstest.MapFS{
 			"page.vuego": &fstest.MapFile{Data: []byte(`<div>Hello</div>`)},
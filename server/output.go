@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/titpetric/vuego/diff"
+	"github.com/titpetric/vuego/formatter"
+)
+
+// Output formats a render request may ask for.
+const (
+	// OutputHTML returns the rendered HTML as-is. This is the default.
+	OutputHTML = "html"
+	// OutputFormatted returns the HTML pretty-printed by the vuego formatter.
+	OutputFormatted = "formatted"
+	// OutputNormalized returns canonical HTML suitable for snapshot comparison.
+	OutputNormalized = "normalized"
+	// OutputYAML returns the DOM tree of the rendered HTML as YAML.
+	OutputYAML = "yaml"
+)
+
+// validateOutput checks that output names a supported output format.
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputHTML, OutputFormatted, OutputNormalized, OutputYAML:
+		return nil
+	}
+	return badRequest(fmt.Errorf("unknown output %q (use: %s, %s, %s, %s)", output, OutputHTML, OutputFormatted, OutputNormalized, OutputYAML))
+}
+
+// formatOutput converts rendered HTML into the requested output format.
+func formatOutput(html, output string) (string, error) {
+	switch output {
+	case "", OutputHTML:
+		return html, nil
+	case OutputFormatted:
+		formatted, err := formatter.NewFormatter().Format(html)
+		if err != nil {
+			return "", fmt.Errorf("formatting output: %w", err)
+		}
+		return formatted, nil
+	case OutputNormalized:
+		normalized, err := diff.FormatToNormalizedHTML([]byte(html))
+		if err != nil {
+			return "", fmt.Errorf("normalizing output: %w", err)
+		}
+		return string(normalized), nil
+	case OutputYAML:
+		return diff.DomToYAML([]byte(html)), nil
+	}
+	return "", validateOutput(output)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// statusError carries the HTTP status code an error should be reported with.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e statusError) Error() string { return e.err.Error() }
+
+func (e statusError) Unwrap() error { return e.err }
+
+func badRequest(err error) error {
+	return statusError{status: http.StatusBadRequest, err: err}
+}
+
+func unprocessable(err error) error {
+	return statusError{status: http.StatusUnprocessableEntity, err: err}
+}
+
+// StatusCode returns the HTTP status code for an error returned by Render.
+// Client errors (bad data, invalid files, template errors) map to 4xx,
+// timeouts map to 504, and anything else is reported as 500.
+func StatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	var statusErr statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status
+	}
+	return http.StatusInternalServerError
+}
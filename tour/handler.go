@@ -109,7 +109,11 @@ func (m *Module) Mount(_ context.Context, r platform.Router) error {
 	r.Get("/lesson/{chapter}/{lesson}", m.serveLessonPage)
 	r.Get("/static/tour.js", m.serveJS)
 	r.Get("/static/tour.css", m.serveCSS)
-	r.Post("/render", m.handleRender)
+	renderOpts := server.WithRenderLoadOption(vuego.WithLessProcessor())
+	r.Post("/render", server.NewRenderHandler(m.tourFS, renderOpts).ServeHTTP)
+	r.Post("/render/batch", server.NewBatchHandler(m.tourFS, renderOpts).ServeHTTP)
+	r.Post("/api/v1/render", server.NewRenderV1Handler(m.tourFS, renderOpts).ServeHTTP)
+	r.Get("/api/v1/openapi.json", server.OpenAPIHandler)
 
 	return nil
 }
@@ -150,29 +154,6 @@ func (m *Module) serveLessonPage(w http.ResponseWriter, r *http.Request) {
 	m.renderTourPage(w, lesson, "", false)
 }
 
-func (m *Module) handleRender(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	if r.Method != http.MethodPost {
-		_ = json.NewEncoder(w).Encode(server.RenderResponse{Error: "method not allowed"})
-		return
-	}
-
-	var req server.RenderRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		_ = json.NewEncoder(w).Encode(server.RenderResponse{Error: "invalid JSON: " + err.Error()})
-		return
-	}
-
-	html, err := server.Render(r.Context(), m.tourFS, req, vuego.WithLessProcessor())
-	if err != nil {
-		_ = json.NewEncoder(w).Encode(server.RenderResponse{Error: err.Error()})
-		return
-	}
-
-	_ = json.NewEncoder(w).Encode(server.RenderResponse{HTML: html})
-}
-
 func (m *Module) serveJS(w http.ResponseWriter, _ *http.Request) {
 	data, _ := embeddedPublic.ReadFile("public/tour.js")
 	w.Header().Set("Content-Type", "application/javascript")
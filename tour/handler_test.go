@@ -108,7 +108,7 @@ func TestModule_RenderEndpoint_InvalidJSON(t *testing.T) {
 
 	handler.ServeHTTP(rec, req)
 
-	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
 
 	var resp map[string]string
 	err := json.NewDecoder(rec.Body).Decode(&resp)
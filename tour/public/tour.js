@@ -147,13 +147,15 @@ function syncFiles() {
 async function render() {
   syncFiles();
 
+  let name = '';
   let template = '';
   let data = '{}';
   
-  for (const [name, content] of Object.entries(files)) {
-    if (name.endsWith('.vuego')) {
+  for (const [filename, content] of Object.entries(files)) {
+    if (filename.endsWith('.vuego')) {
+      name = filename;
       template = content;
-    } else if (name.endsWith('.json') || name.endsWith('.yml') || name.endsWith('.yaml')) {
+    } else if (filename.endsWith('.json') || filename.endsWith('.yml') || filename.endsWith('.yaml')) {
       data = content;
     }
   }
@@ -161,7 +163,7 @@ async function render() {
   const res = await fetch('/render', {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify({ template, data, files })
+    body: JSON.stringify({ name, template, data, files })
   });
   
   const result = await res.json();